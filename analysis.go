@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -8,35 +9,45 @@ import (
 
 // CandleDataSummary represents a summarized view of candlestick data
 type CandleDataSummary struct {
-	Interval     BinanceInterval
-	CandleCount  int
-	StartTime    time.Time
-	EndTime      time.Time
-	Open         float64 // First candle open
-	High         float64 // Highest high
-	Low          float64 // Lowest low
-	Close        float64 // Last candle close
-	TotalVolume  float64
-	AvgVolume    float64
-	PriceChange  float64 // Percentage change
-	Trend        string  // BULLISH, BEARISH, SIDEWAYS
-	MA20         float64
-	MA50         float64
-	RSI          float64
-	Volatility   string // LOW, MEDIUM, HIGH
-	LastCandles  []CandleSimple // Last 10 candles for pattern recognition
+	Interval      BinanceInterval
+	CandleCount   int
+	StartTime     time.Time
+	EndTime       time.Time
+	Open          float64 // First candle open
+	High          float64 // Highest high
+	Low           float64 // Lowest low
+	Close         float64 // Last candle close
+	TotalVolume   float64
+	AvgVolume     float64
+	PriceChange   float64 // Percentage change
+	Trend         string  // BULLISH, BEARISH, SIDEWAYS (from Regime)
+	MA20          float64
+	MA50          float64
+	RSI           float64           // Wilder RSI(14)
+	ATR           float64           // Wilder ATR(14) on true range
+	Volatility    string            // LOW, MEDIUM, HIGH (ATR percentile of the history)
+	Regime        *MarketRegime     // Trend strength, TRENDING/RANGING/BREAKOUT state and volatility percentile (nil while warming up)
+	LastCandles   []CandleSimple    // Last 10 candles for pattern recognition
+	OrderFlow     *OrderFlowMetrics // Taker buy/sell metrics (nil without taker data)
+	Quality       DataQualityReport // Gaps, synthetic bars and staleness of the fetched candles
+	SMC           *SMCAnalysis      // Market structure and zones over the full history (nil if too short)
+	VolumeProfile *VolumeProfile    // Volume at price over the full history (nil without volume)
+	Divergences   []Divergence      // Recent RSI/MACD/OBV divergences at the SMC swings
+	Fibonacci     *FibonacciLevels  // Retracement/extension of the latest significant leg (nil if none)
+	Pivots        *PivotPoints      // Pivots from the last completed candle (1d and 1w only), set with the market calendar
 }
 
 // CandleSimple is a simplified candle for the prompt
 type CandleSimple struct {
-	Time   string
-	O      float64
-	H      float64
-	L      float64
-	C      float64
-	Vol    float64
-	Change float64 // vs previous close
-	Type   string  // BULL, BEAR, DOJI
+	Time     string
+	O        float64
+	H        float64
+	L        float64
+	C        float64
+	Vol      float64
+	Change   float64         // vs previous close
+	Type     string          // BULL, BEAR, DOJI
+	Patterns []CandlePattern // Patterns completed on this candle, strongest first
 }
 
 // AnalyzeCandlestickData creates a summary from raw candlestick data
@@ -73,69 +84,37 @@ func AnalyzeCandlestickData(candles []Candlestick, interval BinanceInterval) Can
 		summary.PriceChange = ((summary.Close - summary.Open) / summary.Open) * 100
 	}
 
-	// Calculate MAs
-	if len(candles) >= 20 {
-		sum20 := 0.0
-		for i := len(candles) - 20; i < len(candles); i++ {
-			sum20 += candles[i].Close
-		}
-		summary.MA20 = sum20 / 20
-	}
-
-	if len(candles) >= 50 {
-		sum50 := 0.0
-		for i := len(candles) - 50; i < len(candles); i++ {
-			sum50 += candles[i].Close
-		}
-		summary.MA50 = sum50 / 50
-	}
-
-	// Calculate RSI (14 period)
-	if len(candles) >= 15 {
-		gains, losses := 0.0, 0.0
-		for i := len(candles) - 14; i < len(candles); i++ {
-			change := candles[i].Close - candles[i-1].Close
-			if change > 0 {
-				gains += change
-			} else {
-				losses -= change
-			}
-		}
-		avgGain := gains / 14
-		avgLoss := losses / 14
-		if avgLoss > 0 {
-			rs := avgGain / avgLoss
-			summary.RSI = 100 - (100 / (1 + rs))
-		} else {
-			summary.RSI = 100
-		}
-	}
-
-	// Determine Trend
-	if summary.Close > summary.MA20 && summary.MA20 > summary.MA50 {
-		summary.Trend = "BULLISH"
-	} else if summary.Close < summary.MA20 && summary.MA20 < summary.MA50 {
-		summary.Trend = "BEARISH"
+	// Indicators (see indicators.go); 0 while still warming up
+	closes := Closes(candles)
+	summary.MA20 = lastOrZero(SMA(closes, 20))
+	summary.MA50 = lastOrZero(SMA(closes, 50))
+	summary.RSI = lastOrZero(RSI(closes, rsiPeriod))
+	atr := ATR(candles, atrPeriod)
+	summary.ATR = lastOrZero(atr)
+
+	// Trend and volatility from the regime classifier (see regime.go); volatility only needs
+	// ATR, so histories too short for the regime still get it
+	summary.Trend = "SIDEWAYS"
+	summary.Regime = ClassifyRegime(candles)
+	if summary.Regime != nil {
+		summary.Trend = summary.Regime.Trend()
+		summary.Volatility = summary.Regime.Volatility
+	} else if volatility, _ := ClassifyVolatility(candles, atr); volatility != "" {
+		summary.Volatility = volatility
 	} else {
-		summary.Trend = "SIDEWAYS"
+		summary.Volatility = "UNKNOWN (warming up)"
 	}
 
-	// Calculate Volatility (ATR-based)
-	if len(candles) >= 14 {
-		atrSum := 0.0
-		for i := len(candles) - 14; i < len(candles); i++ {
-			atrSum += candles[i].High - candles[i].Low
-		}
-		atr := atrSum / 14
-		atrPercent := (atr / summary.Close) * 100
-		if atrPercent > 3 {
-			summary.Volatility = "HIGH"
-		} else if atrPercent > 1.5 {
-			summary.Volatility = "MEDIUM"
-		} else {
-			summary.Volatility = "LOW"
-		}
+	// Smart Money Concepts over the full history
+	summary.SMC = DetectSMC(candles)
+	if summary.SMC != nil {
+		summary.Divergences = DetectDivergences(candles, summary.SMC.Swings)
+		summary.Fibonacci = DetectFibonacci(candles, summary.SMC.Swings, summary.ATR)
 	}
+	summary.VolumeProfile = ComputeVolumeProfile(candles, vpDefaultBins)
+
+	// Order flow (Binance klines only)
+	summary.OrderFlow = AnalyzeOrderFlow(candles)
 
 	// Last 10 candles for pattern recognition
 	startIdx := len(candles) - 10
@@ -172,14 +151,66 @@ func AnalyzeCandlestickData(candles []Candlestick, interval BinanceInterval) Can
 			}
 		}
 		
+		cs.Patterns = DetectCandlePatterns(candles, i)
+		
 		summary.LastCandles = append(summary.LastCandles, cs)
 	}
 
 	return summary
 }
 
+// writeTimeframeSection writes the section of one timeframe, shared by the crypto, forex and
+// stock data contexts; optional parts (order flow, SMC, volume profile, ...) appear when available
+func writeTimeframeSection(sb *strings.Builder, s CandleDataSummary, prec InstrumentPrecision) {
+	p := prec.Format
+	sb.WriteString(fmt.Sprintf("--- %s TIMEFRAME ---\n", GetTimeframeName(s.Interval)))
+	sb.WriteString(fmt.Sprintf("Period: %s to %s\n", s.StartTime.Format("2006-01-02 15:04"), s.EndTime.Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("Candles Analyzed: %d\n", s.CandleCount))
+	sb.WriteString(fmt.Sprintf("Open: %s | High: %s | Low: %s | Close: %s\n", p(s.Open), p(s.High), p(s.Low), p(s.Close)))
+	if prec.PipSize > 0 {
+		sb.WriteString(fmt.Sprintf("Price Change: %.2f%% | Range: %.1f pips\n", s.PriceChange, prec.Pips(s.High-s.Low)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Price Change: %.2f%%\n", s.PriceChange))
+	}
+	sb.WriteString(fmt.Sprintf("MA20: %s | MA50: %s\n", p(s.MA20), p(s.MA50)))
+	sb.WriteString(fmt.Sprintf("RSI(14): %.1f | ATR(14): %s\n", s.RSI, p(s.ATR)))
+	if s.Regime != nil {
+		sb.WriteString(FormatRegimeForAI(s.Regime))
+	} else {
+		sb.WriteString(fmt.Sprintf("Trend: %s | Volatility: %s\n", s.Trend, s.Volatility))
+	}
+	if s.AvgVolume > 0 {
+		sb.WriteString(fmt.Sprintf("Avg Volume: %.2f\n", s.AvgVolume))
+	}
+	if s.OrderFlow != nil {
+		sb.WriteString(FormatOrderFlowForAI(s.OrderFlow))
+	}
+	if s.SMC != nil {
+		sb.WriteString(FormatSMCForAI(s.SMC, prec))
+		sb.WriteString(FormatDivergencesForAI(s.Divergences, prec))
+	}
+	if s.VolumeProfile != nil {
+		sb.WriteString(FormatVolumeProfileForAI(s.VolumeProfile, s.Close, prec))
+	}
+	if s.Fibonacci != nil {
+		sb.WriteString(FormatFibonacciForAI(s.Fibonacci, s.Close, prec))
+	}
+	if s.Pivots != nil {
+		sb.WriteString(FormatPivotPointsForAI(s.Pivots, prec))
+	}
+
+	// Last candles
+	sb.WriteString("Last 10 Candles (Time|O|H|L|C|Change|Type|Patterns strength 0-100 (prior trend)):\n")
+	for _, c := range s.LastCandles {
+		sb.WriteString(fmt.Sprintf("  %s | %s | %s | %s | %s | %+.2f%% | %s%s\n",
+			c.Time, p(c.O), p(c.H), p(c.L), p(c.C), c.Change, c.Type, FormatCandlePatterns(c.Patterns)))
+	}
+	sb.WriteString("\n")
+}
+
 // FormatDataForAI formats multiple timeframe data into a structured prompt
-func FormatDataForAI(symbol string, summaries []CandleDataSummary, mode TradingMode) string {
+// book is optional and adds the order book section when not nil
+func FormatDataForAI(symbol string, prec InstrumentPrecision, summaries []CandleDataSummary, mode TradingMode, book *OrderBookSummary) string {
 	var sb strings.Builder
 	
 	sb.WriteString(fmt.Sprintf("=== MULTI-TIMEFRAME DATA ANALYSIS ===\n"))
@@ -188,30 +219,32 @@ func FormatDataForAI(symbol string, summaries []CandleDataSummary, mode TradingM
 	sb.WriteString(fmt.Sprintf("Data Generated: %s UTC\n\n", time.Now().UTC().Format("2006-01-02 15:04:05")))
 
 	for _, s := range summaries {
-		sb.WriteString(fmt.Sprintf("--- %s TIMEFRAME ---\n", GetTimeframeName(s.Interval)))
-		sb.WriteString(fmt.Sprintf("Period: %s to %s\n", s.StartTime.Format("2006-01-02 15:04"), s.EndTime.Format("2006-01-02 15:04")))
-		sb.WriteString(fmt.Sprintf("Candles Analyzed: %d\n", s.CandleCount))
-		sb.WriteString(fmt.Sprintf("Open: %.8f | High: %.8f | Low: %.8f | Close: %.8f\n", s.Open, s.High, s.Low, s.Close))
-		sb.WriteString(fmt.Sprintf("Price Change: %.2f%%\n", s.PriceChange))
-		sb.WriteString(fmt.Sprintf("MA20: %.8f | MA50: %.8f\n", s.MA20, s.MA50))
-		sb.WriteString(fmt.Sprintf("RSI(14): %.1f\n", s.RSI))
-		sb.WriteString(fmt.Sprintf("Trend: %s | Volatility: %s\n", s.Trend, s.Volatility))
-		sb.WriteString(fmt.Sprintf("Avg Volume: %.2f\n", s.AvgVolume))
-		
-		// Last candles
-		sb.WriteString("Last 10 Candles (Time|O|H|L|C|Change|Type):\n")
-		for _, c := range s.LastCandles {
-			sb.WriteString(fmt.Sprintf("  %s | %.6f | %.6f | %.6f | %.6f | %+.2f%% | %s\n", 
-				c.Time, c.O, c.H, c.L, c.C, c.Change, c.Type))
-		}
-		sb.WriteString("\n")
+		writeTimeframeSection(&sb, s, prec)
+	}
+
+	sb.WriteString(FormatSupportResistanceForAI(DetectSupportResistance(summaries), prec))
+
+	if book != nil {
+		sb.WriteString(FormatOrderBookForAI(*book, prec))
 	}
 
 	return sb.String()
 }
 
+// promptRegimeRule is the LANGKAH 2 rule for the Regime lines, shared by the crypto, forex and stock prompts
+const promptRegimeRule = "- Gunakan Regime tiap timeframe: TRENDING = ikuti trend (entry di pullback), RANGING = buy di support / sell di resistance, BREAKOUT = tunggu retest; Strength tinggi = trend kuat, Volatility/ATR percentile tinggi = SL lebih lebar"
+
+// promptDataRules are the LANGKAH 3 rules for the detected sections of the data context
+// (see writeTimeframeSection), shared by the crypto, forex and stock prompts
+const promptDataRules = `- Fibonacci (Fib Leg/Retracement/Extension) dan Pivot Points DAILY/WEEKLY (Classic/Camarilla/Woodie) di DATA: retracement 0.382-0.618 = area entry pullback, extension 1.272/1.618 dan pivot R/S = kandidat TP; level yang berdekatan dengan S/R atau zona SMC = confluence kuat
+- Divergence WAJIB diambil dari baris Divergences di DATA (REGULAR = sinyal reversal, HIDDEN = sinyal continuation); jika "none", jangan klaim ada divergence
+- Gunakan pola candle di kolom Patterns (skor 0-100, konteks trend sebelumnya) sebagai konfirmasi rejection/entry; jangan klaim pola yang tidak terdeteksi
+- Key Support/Resistance WAJIB diambil dari SUPPORT / RESISTANCE LEVELS di DATA (S1 = support terdekat, R1 = resistance terdekat; score tinggi = level kuat)
+- Zona SMC di DATA (Structure Bias, Recent Breaks, OB, FVG, Liquidity) terdeteksi otomatis dari seluruh histori candle: jadikan acuan utama, jangan mengarang zona lain
+- Jika Volume Profile tersedia: POC/HVN = magnet & area reaksi, LVN = area harga bergerak cepat, VAH/VAL = batas value area untuk entry dan target`
+
 // GenerateDataAnalysisPrompt creates a prompt for data-based analysis (matching manual flow)
-func GenerateDataAnalysisPrompt(mode TradingMode, symbol string, dataContext string) string {
+func GenerateDataAnalysisPrompt(mode TradingMode, symbol string, prec InstrumentPrecision, dataContext string) string {
 	baseRole := ""
 	strategy := ""
 
@@ -257,15 +290,20 @@ LANGKAH 1: EXTERNAL DATA VALIDATION
 
 LANGKAH 2: MULTI-TIMEFRAME ANALYSIS
 - Analisa dari timeframe TERBESAR ke TERKECIL
+%s
 - Identifikasi: Trend utama di HTF (Higher Time Frame)
 - Cari entry presisi di LTF (Lower Time Frame)
 - Pastikan confluence antara HTF dan LTF
 
 LANGKAH 3: SMART MONEY ANALYSIS
+%s
 - Order Blocks (OB) - zona akumulasi institusional
 - Fair Value Gaps (FVG) / Imbalance
 - Break of Structure (BOS) / Change of Character (ChoCh)
 - Liquidity zones (Equal highs/lows yang akan di-sweep)
+- Gunakan Taker Buy/Sell, CVD dan Divergence untuk membedakan breakout asli vs absorption
+- Jika ORDER BOOK tersedia: gunakan Bid/Ask Walls dan Cumulative Depth sebagai konfirmasi zona likuiditas
+- Jika FUTURES DATA tersedia: gunakan Funding Rate, Open Interest, Long/Short Ratio, Basis Perp vs Spot dan Likuidasi (angka minimum, bukan total) untuk membaca posisi yang crowded (kandidat squeeze)
 
 LANGKAH 4: ENTRY SETUP
 - Entry Point yang optimal (harga spesifik)
@@ -278,7 +316,7 @@ CRITICAL RULE:
 1. GUNAKAN FORMAT HTML (Telegram Compatible).
 2. Escape karakter < > & di dalam teks biasa.
 3. GUNAKAN Code Block "diff" untuk warna merah/hijau.
-4. BERIKAN HARGA SPESIFIK untuk Entry, SL, TP (bukan range).
+4. BERIKAN HARGA SPESIFIK untuk Entry, SL, TP (bukan range, %s).
 --------------------------------------------------------
 
 OUTPUT FORMAT (STRICT HTML):
@@ -295,6 +333,8 @@ HTF Trend (1D/4H): <b>[BULLISH/BEARISH]</b>
 LTF Trend (1H/15m): <b>[BULLISH/BEARISH]</b>
 Key Support: [level harga]
 Key Resistance: [level harga]
+Demand Zone: [harga bawah] - [harga atas]
+Supply Zone: [harga bawah] - [harga atas]
 Volatility: [Low/Med/High]
 
 <b>💎 SIGNAL CARD</b>
@@ -322,23 +362,10 @@ Volatility: [Low/Med/High]
 
 ---
 <i>Generated by Antigravity AI • Data-Based Analysis</i>
-`, baseRole, dataContext, strategy, symbol, symbol, getTradingModeName(mode), getTradingModeName(mode))
+`, baseRole, dataContext, strategy, symbol, promptRegimeRule, promptDataRules, prec.Describe(), symbol, getTradingModeName(mode), getTradingModeName(mode))
 }
 
 // FetchMultiTimeframeData fetches data for all timeframes without generating images
-func FetchMultiTimeframeData(symbol string, mode TradingMode, candleLimit int) ([]CandleDataSummary, error) {
-	timeframes := GetTimeframesForMode(mode)
-	summaries := make([]CandleDataSummary, 0, len(timeframes))
-
-	for _, tf := range timeframes {
-		candles, err := FetchCandlesticks(symbol, tf, candleLimit)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch %s: %w", tf, err)
-		}
-		
-		summary := AnalyzeCandlestickData(candles, tf)
-		summaries = append(summaries, summary)
-	}
-
-	return summaries, nil
+func FetchMultiTimeframeData(ctx context.Context, symbol string, mode TradingMode, candleLimit int) (MultiTimeframeResult, error) {
+	return FetchProviderMultiTimeframeData(ctx, NewCachedProvider(BinanceProvider{}, DefaultCandleCache), symbol, GetTimeframesForMarket(MarketCrypto, mode), candleLimit)
 }
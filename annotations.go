@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AnnotationKind selects how a chart annotation is drawn
+type AnnotationKind int
+
+const (
+	AnnotationZone      AnnotationKind = iota // filled box between Bottom and Top
+	AnnotationLevel                           // horizontal line at Price
+	AnnotationMarker                          // triangle at Price on the From candle
+	AnnotationTrendLine                       // line from (From, Price) to (To, EndPrice)
+)
+
+// Annotation sources
+const (
+	SourceAlgo = "algo" // detected from candles
+	SourceAI   = "ai"   // proposed in the Gemini response
+)
+
+// smcMaxSwingMarkers is the number of most recent swing points marked on the chart
+const smcMaxSwingMarkers = 10
+
+// ChartAnnotation is one overlay drawn on top of the candles
+// From/To are candle times; a zero From starts at the left edge and a zero To extends to the right edge
+type ChartAnnotation struct {
+	Kind     AnnotationKind
+	Label    string
+	Source   string
+	From     time.Time
+	To       time.Time
+	Top      float64 // zone top
+	Bottom   float64 // zone bottom
+	Price    float64 // level/marker/trend line start price
+	EndPrice float64 // trend line end price
+	Color    color.NRGBA
+	Dashed   bool // levels only
+	Below    bool // markers and trend lines: drawn under the price (swing lows)
+}
+
+// Colors for SMC and AI overlays
+var (
+	colorOBBullish  = color.NRGBA{R: 38, G: 166, B: 91, A: 60}
+	colorOBBearish  = color.NRGBA{R: 231, G: 76, B: 60, A: 60}
+	colorFVGBullish = color.NRGBA{R: 0, G: 188, B: 212, A: 45}
+	colorFVGBearish = color.NRGBA{R: 255, G: 152, B: 0, A: 45}
+	colorStructure  = color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+	colorSwing      = color.NRGBA{R: 120, G: 144, B: 156, A: 255}
+	colorLiquidity  = color.NRGBA{R: 255, G: 213, B: 79, A: 255}
+	colorAIDemand   = color.NRGBA{R: 33, G: 150, B: 243, A: 55}
+	colorAISupply   = color.NRGBA{R: 233, G: 30, B: 99, A: 55}
+)
+
+// SMCAnnotations converts an SMC analysis into chart overlays: active order blocks,
+// the unfilled part of open FVGs, recent BOS/CHoCH, swing markers and unswept liquidity
+func SMCAnnotations(a *SMCAnalysis) []ChartAnnotation {
+	if a == nil {
+		return nil
+	}
+	var out []ChartAnnotation
+
+	for _, dir := range []string{SMCBullish, SMCBearish} {
+		obColor, fvgColor := colorOBBullish, colorFVGBullish
+		if dir == SMCBearish {
+			obColor, fvgColor = colorOBBearish, colorFVGBearish
+		}
+		for _, ob := range a.ActiveOrderBlocks(dir) {
+			out = append(out, ChartAnnotation{Kind: AnnotationZone, Label: "OB", Source: SourceAlgo,
+				From: ob.Time, Top: ob.Top, Bottom: ob.Bottom, Color: obColor})
+		}
+		for _, gap := range a.OpenFVGs(dir) {
+			// Only the part of the gap that is still open
+			top, bottom := gap.Top, gap.Bottom
+			if dir == SMCBullish {
+				top -= (gap.Top - gap.Bottom) * gap.Filled
+			} else {
+				bottom += (gap.Top - gap.Bottom) * gap.Filled
+			}
+			out = append(out, ChartAnnotation{Kind: AnnotationZone, Label: "FVG", Source: SourceAlgo,
+				From: gap.Time, Top: top, Bottom: bottom, Color: fvgColor})
+		}
+	}
+
+	if n := len(a.Structure); n > 0 {
+		for _, b := range a.Structure[max(0, n-smcMaxReported):] {
+			label := b.Kind
+			if b.Kind == "CHOCH" {
+				label = "CHoCH"
+			}
+			out = append(out, ChartAnnotation{Kind: AnnotationLevel, Label: label, Source: SourceAlgo,
+				From: b.SwingTime, To: b.Time, Price: b.Level, Color: colorStructure, Dashed: true})
+		}
+	}
+
+	for _, s := range a.Swings[max(0, len(a.Swings)-smcMaxSwingMarkers):] {
+		out = append(out, ChartAnnotation{Kind: AnnotationMarker, Source: SourceAlgo,
+			From: s.Time, Price: s.Price, Color: colorSwing, Below: !s.High})
+	}
+
+	for _, lp := range a.Liquidity {
+		if lp.Swept {
+			continue
+		}
+		out = append(out, ChartAnnotation{Kind: AnnotationLevel, Label: lp.Kind, Source: SourceAlgo,
+			From: lp.First, Price: lp.Price, Color: colorLiquidity, Dashed: true})
+	}
+
+	return out
+}
+
+// SummaryAnnotations returns the SMC, divergence and Fibonacci overlays detected over the full
+// history of the summary for interval, limited to a chart window starting at from
+func SummaryAnnotations(summaries []CandleDataSummary, interval BinanceInterval, from time.Time) []ChartAnnotation {
+	for _, s := range summaries {
+		if s.Interval != interval || s.SMC == nil {
+			continue
+		}
+		out := SMCAnnotations(s.SMC.Since(from))
+		var divs []Divergence
+		for _, d := range s.Divergences {
+			if !d.From.Time.Before(from) {
+				divs = append(divs, d)
+			}
+		}
+		out = append(out, DivergenceAnnotations(divs)...)
+		return append(out, FibonacciAnnotations(s.Fibonacci)...)
+	}
+	return nil
+}
+
+// aiZoneRe matches zone lines such as "Demand Zone: 98,200 - 98,650" or "Order Block: 1.0840-1.0852"
+var aiZoneRe = regexp.MustCompile(`(?i)(demand zone|supply zone|order block|fvg)[^:\n0-9]*:\s*\[?([0-9][0-9.,]*)\]?\s*(?:-|–|to|s/d)\s*\[?([0-9][0-9.,]*)`)
+
+// ParseZonesFromResponse extracts the zones proposed in the AI response as chart annotations
+// Demand zones are drawn blue and supply zones pink; other zones take the color of their side of price
+func ParseZonesFromResponse(text string, prec InstrumentPrecision, lastClose float64) []ChartAnnotation {
+	var out []ChartAnnotation
+	for _, m := range aiZoneRe.FindAllStringSubmatch(text, -1) {
+		a, errA := parsePriceNumber(strings.TrimRight(m[2], ".,"))
+		b, errB := parsePriceNumber(strings.TrimRight(m[3], ".,"))
+		if errA != nil || errB != nil || a <= 0 || b <= 0 {
+			continue
+		}
+		if a > b {
+			a, b = b, a
+		}
+
+		kind := strings.ToLower(m[1])
+		demand := kind == "demand zone" || (kind != "supply zone" && b <= lastClose)
+		zoneColor, label := colorAIDemand, "AI DEMAND"
+		if !demand {
+			zoneColor, label = colorAISupply, "AI SUPPLY"
+		}
+		out = append(out, ChartAnnotation{Kind: AnnotationZone, Label: label, Source: SourceAI,
+			Top: prec.Round(b), Bottom: prec.Round(a), Color: zoneColor})
+	}
+	return out
+}
+
+// chartLayout maps candle times and prices to pixels of the plot area
+type chartLayout struct {
+	candles          []Candlestick // visible candles
+	left, right      int
+	top, height      int
+	totalCandleWidth int
+	maxPrice         float64
+	priceRange       float64
+}
+
+// y returns the pixel row of a price
+func (l chartLayout) y(price float64) int {
+	return l.top + int((l.maxPrice-price)/l.priceRange*float64(l.height))
+}
+
+// x returns the pixel column of the candle open at or before t (left edge for earlier or zero times)
+func (l chartLayout) x(t time.Time) int {
+	if t.IsZero() {
+		return l.left
+	}
+	i := sort.Search(len(l.candles), func(i int) bool { return l.candles[i].OpenTime.After(t) }) - 1
+	if i < 0 {
+		return l.left
+	}
+	return l.left + i*l.totalCandleWidth + l.totalCandleWidth/2
+}
+
+// xEnd returns the column for an end time (right edge when zero)
+func (l chartLayout) xEnd(t time.Time) int {
+	if t.IsZero() {
+		return l.right
+	}
+	return l.x(t)
+}
+
+// visible reports whether a price band overlaps the plotted range
+func (l chartLayout) visible(low, high float64) bool {
+	return high >= l.maxPrice-l.priceRange && low <= l.maxPrice
+}
+
+// drawAnnotations renders the overlays; zones first so that lines and labels stay on top
+func drawAnnotations(img *image.RGBA, annotations []ChartAnnotation, l chartLayout, format func(float64) string) {
+	plot := image.Rect(l.left, l.top, l.right, l.top+l.height)
+	sorted := append([]ChartAnnotation{}, annotations...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Kind < sorted[j].Kind })
+
+	for _, a := range sorted {
+		switch a.Kind {
+		case AnnotationZone:
+			if !l.visible(a.Bottom, a.Top) {
+				continue
+			}
+			box := image.Rect(l.x(a.From), l.y(a.Top), l.xEnd(a.To), l.y(a.Bottom)).Intersect(plot)
+			if box.Dy() < 1 {
+				box.Max.Y = box.Min.Y + 1
+			}
+			draw.Draw(img, box, &image.Uniform{a.Color}, image.Point{}, draw.Over)
+			edge := opaque(a.Color)
+			drawLine(img, box.Min.X, box.Min.Y, box.Max.X, box.Min.Y, edge)
+			drawLine(img, box.Min.X, box.Max.Y, box.Max.X, box.Max.Y, edge)
+			drawText(img, box.Min.X+3, box.Min.Y+11, a.Label, edge)
+
+		case AnnotationLevel:
+			if !l.visible(a.Price, a.Price) {
+				continue
+			}
+			x1, x2, y := l.x(a.From), l.xEnd(a.To), l.y(a.Price)
+			step := 1
+			if a.Dashed {
+				step = 4
+			}
+			for x := x1; x <= x2; x += step {
+				img.Set(x, y, a.Color)
+				if a.Dashed {
+					img.Set(x+1, y, a.Color)
+				}
+			}
+			if a.Label != "" {
+				label := a.Label
+				if a.To.IsZero() {
+					label = fmt.Sprintf("%s %s", a.Label, format(a.Price))
+				}
+				drawText(img, (x1+x2)/2-len(label)*7/2, y-3, label, opaque(a.Color))
+			}
+
+		case AnnotationMarker:
+			if !l.visible(a.Price, a.Price) || a.From.Before(l.candles[0].OpenTime) {
+				continue
+			}
+			drawTriangle(img, l.x(a.From), l.y(a.Price), a.Below, a.Color)
+
+		case AnnotationTrendLine:
+			if !l.visible(a.Price, a.Price) || !l.visible(a.EndPrice, a.EndPrice) || a.From.Before(l.candles[0].OpenTime) {
+				continue
+			}
+			// Offset from the wicks so the line does not hide the swing candles
+			offset := -6
+			if a.Below {
+				offset = 6
+			}
+			x1, y1 := l.x(a.From), l.y(a.Price)+offset
+			x2, y2 := l.x(a.To), l.y(a.EndPrice)+offset
+			drawLineBresenham(img, x1, y1, x2, y2, a.Color)
+			drawLineBresenham(img, x1, y1+1, x2, y2+1, a.Color)
+			labelY := y2 - 4
+			if a.Below {
+				labelY = y2 + 14
+			}
+			drawText(img, x2-len(a.Label)*7, labelY, a.Label, a.Color)
+		}
+	}
+}
+
+// drawTriangle draws a small marker pointing at (x, y) from above, or from below when below is set
+func drawTriangle(img *image.RGBA, x, y int, below bool, c color.Color) {
+	const size = 4
+	for row := 0; row <= size; row++ {
+		yy := y - 3 - row
+		if below {
+			yy = y + 3 + row
+		}
+		for dx := -row; dx <= row; dx++ {
+			img.Set(x+dx, yy, c)
+		}
+	}
+}
+
+// opaque returns c with full alpha, used for outlines and labels of translucent zones
+func opaque(c color.NRGBA) color.NRGBA {
+	c.A = 255
+	return c
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,20 +20,27 @@ const (
 )
 
 // binanceBaseURLs is the list of Binance API endpoints to try (with fallback)
+// Overridable for recorded fixtures
 var binanceBaseURLs = []string{
 	BinanceGlobalBaseURL,
 	BinanceUSBaseURL,
 }
 
 // Candlestick represents OHLCV data
+// The order flow fields are only filled by Binance; other providers leave them zero
 type Candlestick struct {
-	OpenTime  time.Time
-	Open      float64
-	High      float64
-	Low       float64
-	Close     float64
-	Volume    float64
-	CloseTime time.Time
+	OpenTime      time.Time
+	Open          float64
+	High          float64
+	Low           float64
+	Close         float64
+	Volume        float64
+	CloseTime     time.Time
+	QuoteVolume   float64 // volume in quote asset
+	Trades        int64   // number of trades
+	TakerBuyBase  float64 // base volume bought by takers (market buys)
+	TakerBuyQuote float64 // quote volume bought by takers
+	Synthetic     bool    // OHLC partly filled because the provider returned nulls
 }
 
 // BinanceInterval represents Binance kline intervals
@@ -113,57 +124,191 @@ func GetTimeframeName(interval BinanceInterval) string {
 	}
 }
 
+// Binance kline request limits
+const (
+	// binanceMaxKlinesPerRequest is the maximum "limit" accepted by /api/v3/klines
+	binanceMaxKlinesPerRequest = 1000
+	// binanceWeightSafetyRatio pauses Binance requests once this share of the weight budget is used
+	binanceWeightSafetyRatio = 0.8
+	// binancePageDelay is the pause between paginated kline requests
+	binancePageDelay = 100 * time.Millisecond
+)
+
+// binanceWeightLimits is the request weight allowed per minute per IP on each Binance API host
+// Every host counts its own X-MBX-USED-WEIGHT-1M; hosts not listed are not tracked
+var binanceWeightLimits = map[string]int{
+	"api.binance.com":  6000,
+	"api.binance.us":   1200,
+	"fapi.binance.com": 2400,
+}
+
+// IntervalDuration returns the duration of one candle for an interval
+func IntervalDuration(interval BinanceInterval) time.Duration {
+	switch interval {
+	case Interval1m:
+		return time.Minute
+	case Interval5m:
+		return 5 * time.Minute
+	case Interval15m:
+		return 15 * time.Minute
+	case Interval30m:
+		return 30 * time.Minute
+	case Interval1h:
+		return time.Hour
+	case Interval4h:
+		return 4 * time.Hour
+	case Interval1d:
+		return 24 * time.Hour
+	case Interval1w:
+		return 7 * 24 * time.Hour
+	default:
+		return time.Hour
+	}
+}
+
 // FetchCandlesticks fetches OHLCV data from Binance API with fallback to Binance US
 // symbol: e.g., "BTCUSDT"
 // interval: e.g., "1h"
-// limit: number of candles (max 1000)
-func FetchCandlesticks(symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
-	if limit > 1000 {
-		limit = 1000
+// limit: number of candles (above 1000 is paginated via FetchCandlesticksHistory)
+func FetchCandlesticks(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	if limit > binanceMaxKlinesPerRequest {
+		return FetchCandlesticksHistory(ctx, symbol, interval, limit)
 	}
 	if limit < 1 {
 		limit = 200
 	}
 
-	var lastErr error
-	var body []byte
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("interval", string(interval))
+	query.Set("limit", strconv.Itoa(limit))
 
-	// Try each Binance endpoint (global first, then US as fallback)
-	for _, baseURL := range binanceBaseURLs {
-		url := fmt.Sprintf(
-			"%s/api/v3/klines?symbol=%s&interval=%s&limit=%d",
-			baseURL, symbol, interval, limit,
-		)
+	body, err := fetchBinanceKlines(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBinanceKlines(body)
+}
 
-		resp, err := http.Get(url)
+// FetchCandlesticksHistory fetches the latest `total` candles, paging past the 1000-candle cap
+// Useful for long lookbacks (e.g., 5000 x 1h) and MA200 on higher timeframes
+func FetchCandlesticksHistory(ctx context.Context, symbol string, interval BinanceInterval, total int) ([]Candlestick, error) {
+	if total <= binanceMaxKlinesPerRequest {
+		return FetchCandlesticks(ctx, symbol, interval, total)
+	}
+
+	end := time.Now()
+	start := end.Add(-time.Duration(total) * IntervalDuration(interval))
+
+	candles, err := FetchCandlesticksRange(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candles) > total {
+		candles = candles[len(candles)-total:]
+	}
+	return candles, nil
+}
+
+// FetchCandlesticksRange fetches all candles with open time in [start, end]
+// Pages through /api/v3/klines with startTime/endTime and de-duplicates by open time
+// DefaultHTTPClient pauses when X-MBX-USED-WEIGHT-1M approaches the per-minute limit
+func FetchCandlesticksRange(ctx context.Context, symbol string, interval BinanceInterval, start, end time.Time) ([]Candlestick, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid range: end %s is not after start %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+
+	seen := make(map[int64]bool)
+	candles := make([]Candlestick, 0)
+	cursor := start
+	endMs := end.UnixMilli()
+
+	for cursor.UnixMilli() <= endMs {
+		query := url.Values{}
+		query.Set("symbol", symbol)
+		query.Set("interval", string(interval))
+		query.Set("startTime", strconv.FormatInt(cursor.UnixMilli(), 10))
+		query.Set("endTime", strconv.FormatInt(endMs, 10))
+		query.Set("limit", strconv.Itoa(binanceMaxKlinesPerRequest))
+
+		body, err := fetchBinanceKlines(ctx, query)
 		if err != nil {
-			lastErr = fmt.Errorf("failed to fetch from %s: %w", baseURL, err)
-			continue
+			return nil, fmt.Errorf("failed to fetch page starting %s: %w", cursor.UTC().Format(time.RFC3339), err)
 		}
 
-		if resp.StatusCode != http.StatusOK {
-			respBody, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			lastErr = fmt.Errorf("binance API error from %s (status %d): %s", baseURL, resp.StatusCode, string(respBody))
-			continue
+		page, err := parseBinanceKlines(body)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			break
 		}
 
-		body, err = io.ReadAll(resp.Body)
-		resp.Body.Close()
+		for _, c := range page {
+			key := c.OpenTime.UnixMilli()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			candles = append(candles, c)
+		}
+
+		// Last page reached
+		if len(page) < binanceMaxKlinesPerRequest {
+			break
+		}
+
+		// Advance cursor past the last candle of this page
+		next := page[len(page)-1].OpenTime.Add(time.Millisecond)
+		if !next.After(cursor) {
+			break
+		}
+		cursor = next
+
+		if !sleepContext(ctx, binancePageDelay) {
+			return nil, ctx.Err()
+		}
+	}
+
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].OpenTime.Before(candles[j].OpenTime)
+	})
+
+	return candles, nil
+}
+
+// fetchBinanceKlines requests /api/v3/klines from each Binance endpoint until one succeeds
+func fetchBinanceKlines(ctx context.Context, query url.Values) ([]byte, error) {
+	return fetchBinanceAPI(ctx, "/api/v3/klines", query)
+}
+
+// fetchBinanceAPI GETs a spot API path, falling back across binanceBaseURLs
+func fetchBinanceAPI(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	var lastErr error
+
+	// Try each Binance endpoint (global first, then US as fallback)
+	for _, baseURL := range binanceBaseURLs {
+		endpoint := fmt.Sprintf("%s%s?%s", baseURL, path, query.Encode())
+
+		body, _, err := DefaultHTTPClient.Get(ctx, endpoint, nil)
 		if err != nil {
-			lastErr = fmt.Errorf("failed to read response from %s: %w", baseURL, err)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = fmt.Errorf("binance API error from %s: %w", baseURL, err)
 			continue
 		}
 
-		// Success! Break out of the loop
-		lastErr = nil
-		break
+		return body, nil
 	}
 
-	if lastErr != nil {
-		return nil, fmt.Errorf("all Binance endpoints failed: %w", lastErr)
-	}
+	return nil, fmt.Errorf("all Binance endpoints failed: %w", lastErr)
+}
 
+// parseBinanceKlines converts the raw klines JSON into candlesticks
+func parseBinanceKlines(body []byte) ([]Candlestick, error) {
 	// Binance returns array of arrays
 	// [OpenTime, Open, High, Low, Close, Volume, CloseTime, QuoteVolume, Trades, TakerBuyBase, TakerBuyQuote, Ignore]
 	var rawData [][]interface{}
@@ -186,7 +331,7 @@ func FetchCandlesticks(symbol string, interval BinanceInterval, limit int) ([]Ca
 		close, _ := strconv.ParseFloat(item[4].(string), 64)
 		volume, _ := strconv.ParseFloat(item[5].(string), 64)
 
-		candles = append(candles, Candlestick{
+		candle := Candlestick{
 			OpenTime:  time.UnixMilli(openTime),
 			Open:      open,
 			High:      high,
@@ -194,47 +339,62 @@ func FetchCandlesticks(symbol string, interval BinanceInterval, limit int) ([]Ca
 			Close:     close,
 			Volume:    volume,
 			CloseTime: time.UnixMilli(closeTime),
-		})
+		}
+
+		if len(item) >= 11 {
+			candle.QuoteVolume, _ = strconv.ParseFloat(item[7].(string), 64)
+			if trades, ok := item[8].(float64); ok {
+				candle.Trades = int64(trades)
+			}
+			candle.TakerBuyBase, _ = strconv.ParseFloat(item[9].(string), 64)
+			candle.TakerBuyQuote, _ = strconv.ParseFloat(item[10].(string), 64)
+		}
+
+		candles = append(candles, candle)
 	}
 
 	return candles, nil
 }
 
 // ValidateSymbol checks if a symbol exists on Binance (with US fallback)
-func ValidateSymbol(symbol string) (bool, error) {
+func ValidateSymbol(ctx context.Context, symbol string) (bool, error) {
+	var lastErr error
+
 	for _, baseURL := range binanceBaseURLs {
 		url := fmt.Sprintf("%s/api/v3/ticker/price?symbol=%s", baseURL, symbol)
 
-		resp, err := http.Get(url)
-		if err != nil {
-			continue
-		}
-		resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
+		_, _, err := DefaultHTTPClient.Get(ctx, url, nil)
+		if err == nil {
 			return true, nil
 		}
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if !IsHTTPStatus(err, http.StatusBadRequest) {
+			lastErr = err
+		}
 	}
 
+	// Unknown symbols are answered with 400; anything else means we could not check
+	if lastErr != nil {
+		return false, fmt.Errorf("could not validate %s: %w", symbol, lastErr)
+	}
 	return false, nil
 }
 
 // GetCurrentPrice fetches the current price of a symbol (with US fallback)
-func GetCurrentPrice(symbol string) (float64, error) {
+func GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
 	var lastErr error
 
 	for _, baseURL := range binanceBaseURLs {
 		url := fmt.Sprintf("%s/api/v3/ticker/price?symbol=%s", baseURL, symbol)
 
-		resp, err := http.Get(url)
+		body, _, err := DefaultHTTPClient.Get(ctx, url, nil)
 		if err != nil {
-			lastErr = err
-			continue
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			lastErr = fmt.Errorf("symbol not found on %s", baseURL)
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			lastErr = fmt.Errorf("symbol not found on %s: %w", baseURL, err)
 			continue
 		}
 
@@ -242,9 +402,6 @@ func GetCurrentPrice(symbol string) (float64, error) {
 			Price string `json:"price"`
 		}
 
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-
 		if err := json.Unmarshal(body, &result); err != nil {
 			lastErr = err
 			continue
@@ -259,3 +416,71 @@ func GetCurrentPrice(symbol string) (float64, error) {
 	}
 	return 0, fmt.Errorf("symbol not found")
 }
+
+// BinanceProvider implements MarketDataProvider for Binance spot markets
+type BinanceProvider struct{}
+
+// Name returns the provider name
+func (BinanceProvider) Name() string { return "Binance" }
+
+// Market returns MarketCrypto
+func (BinanceProvider) Market() Market { return MarketCrypto }
+
+// NormalizeSymbol resolves user input through DefaultSymbolCatalog (e.g., "btc" -> "BTCUSDT")
+// Until the catalog is loaded it only cleans the input (e.g., "btc/usdt" -> "BTCUSDT")
+func (BinanceProvider) NormalizeSymbol(input string) (string, string, error) {
+	if DefaultSymbolCatalog.Loaded() {
+		info, err := DefaultSymbolCatalog.Lookup(input)
+		if err != nil {
+			return "", "", err
+		}
+		return info.Symbol, info.Symbol, nil
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(input))
+	symbol = strings.ReplaceAll(symbol, "/", "")
+	symbol = strings.ReplaceAll(symbol, " ", "")
+	if symbol == "" {
+		return "", "", fmt.Errorf("empty crypto symbol")
+	}
+	return symbol, symbol, nil
+}
+
+// FetchCandles fetches candles via FetchCandlesticks
+func (BinanceProvider) FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	return FetchCandlesticks(ctx, symbol, interval, limit)
+}
+
+// GetCurrentPrice returns the streamed price when DefaultBinanceStream has a fresh mini ticker,
+// otherwise polls GetCurrentPrice and subscribes the ticker so later calls are served by the stream
+func (BinanceProvider) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	if price, ok := DefaultBinanceStream.LastPrice(symbol); ok {
+		return price, nil
+	}
+	price, err := GetCurrentPrice(ctx, symbol)
+	if err == nil {
+		if subErr := DefaultBinanceStream.SubscribeMiniTicker(symbol); subErr != nil {
+			log.Printf("⚠️ [STREAM] Failed to subscribe %s ticker: %v", symbol, subErr)
+		}
+	}
+	return price, err
+}
+
+// ValidateSymbol answers from DefaultSymbolCatalog once loaded, otherwise via ValidateSymbol
+func (BinanceProvider) ValidateSymbol(ctx context.Context, symbol string) (bool, error) {
+	if DefaultSymbolCatalog.Loaded() {
+		info, ok := DefaultSymbolCatalog.Get(symbol)
+		return ok && info.Status == "TRADING", nil
+	}
+	return ValidateSymbol(ctx, symbol)
+}
+
+// SupportedIntervals returns all Binance kline intervals used by the bot
+func (BinanceProvider) SupportedIntervals() []BinanceInterval {
+	return []BinanceInterval{Interval1m, Interval5m, Interval15m, Interval30m, Interval1h, Interval4h, Interval1d, Interval1w}
+}
+
+// Precision returns the exchangeInfo tick size via BinancePrecision
+func (BinanceProvider) Precision(symbol string) InstrumentPrecision {
+	return BinancePrecision(symbol)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useBinanceFixtures points binanceBaseURLs at the given servers for the test
+func useBinanceFixtures(t *testing.T, baseURLs ...string) {
+	t.Helper()
+	saved := binanceBaseURLs
+	binanceBaseURLs = baseURLs
+	t.Cleanup(func() { binanceBaseURLs = saved })
+}
+
+func TestBinanceFallsBackAcrossEndpoints(t *testing.T) {
+	restricted := fixtureStatusServer(t, http.StatusUnavailableForLegalReasons, func(r *http.Request) string {
+		return "binance_restricted.json"
+	})
+	us := fixtureServer(t, func(r *http.Request) string {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v3/klines":
+			if q.Get("symbol") != "BTCUSDT" || q.Get("interval") != "1h" || q.Get("limit") != "2" {
+				t.Errorf("unexpected klines query %s", r.URL.RawQuery)
+			}
+			return "binance_klines.json"
+		case "/api/v3/ticker/price":
+			if q.Get("symbol") == "BTCUSDT" {
+				return "binance_ticker_price.json"
+			}
+		}
+		return ""
+	})
+	useBinanceFixtures(t, restricted.URL, us.URL)
+	ctx := context.Background()
+
+	candles, err := FetchCandlesticks(ctx, "BTCUSDT", Interval1h, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCandleTimes(t, candles, 2, 3)
+	last := candles[1]
+	if last.Open != 60250.5 || last.High != 60410 || last.Low != 60100.1 || last.Close != 60388.2 || last.Volume != 952.431 {
+		t.Errorf("unexpected OHLCV %+v", last)
+	}
+	if last.QuoteVolume != 57396214.53 || last.Trades != 39877 || last.TakerBuyBase != 470.01 || last.TakerBuyQuote != 28330112.9 {
+		t.Errorf("unexpected order flow fields %+v", last)
+	}
+
+	if price, err := GetCurrentPrice(ctx, "BTCUSDT"); err != nil || price != 60388.2 {
+		t.Errorf("GetCurrentPrice = %v, %v; want 60388.2", price, err)
+	}
+}
+
+func TestBinanceValidateSymbol(t *testing.T) {
+	srv := fixtureStatusServer(t, http.StatusBadRequest, func(r *http.Request) string {
+		return "binance_invalid_symbol.json"
+	})
+	useBinanceFixtures(t, srv.URL)
+
+	if listed, err := ValidateSymbol(context.Background(), "NOPEUSDT"); listed || err != nil {
+		t.Errorf("ValidateSymbol of an unknown symbol = %v, %v; want false, nil", listed, err)
+	}
+}
+
+func TestFetchCandlesticksRangePages(t *testing.T) {
+	start := fixtureTime(2).Add(-binanceMaxKlinesPerRequest * time.Hour)
+	end := fixtureTime(3).Add(30 * time.Minute)
+
+	second, err := os.ReadFile(filepath.Join("testdata", "binance_klines.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		q := r.URL.Query()
+		if q.Get("endTime") != strconv.FormatInt(end.UnixMilli(), 10) || q.Get("limit") != "1000" {
+			t.Errorf("unexpected klines query %s", r.URL.RawQuery)
+		}
+		switch q.Get("startTime") {
+		case strconv.FormatInt(start.UnixMilli(), 10):
+			// A full page of 1000 candles from start to 01:00, too large for a fixture file
+			rows := make([]string, binanceMaxKlinesPerRequest)
+			for i := range rows {
+				open := start.Add(time.Duration(i) * time.Hour).UnixMilli()
+				rows[i] = fmt.Sprintf(`[%d,"1","2","0.5","1.5","10",%d,"15",3,"4","6","0"]`, open, open+time.Hour.Milliseconds()-1)
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(rows, ","))
+		case strconv.FormatInt(fixtureTime(1).UnixMilli()+1, 10):
+			w.Write(second)
+		default:
+			t.Errorf("unexpected startTime %s", q.Get("startTime"))
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer srv.Close()
+	useBinanceFixtures(t, srv.URL)
+
+	candles, err := FetchCandlesticksRange(context.Background(), "BTCUSDT", Interval1h, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 2 {
+		t.Errorf("requested %d pages, want 2", pages)
+	}
+	if len(candles) != binanceMaxKlinesPerRequest+2 {
+		t.Fatalf("got %d candles, want %d", len(candles), binanceMaxKlinesPerRequest+2)
+	}
+	for i := 1; i < len(candles); i++ {
+		if got := candles[i].OpenTime.Sub(candles[i-1].OpenTime); got != time.Hour {
+			t.Fatalf("candle %d is %s after the previous one, want 1h", i, got)
+		}
+	}
+	checkCandleTimes(t, candles[len(candles)-2:], 2, 3)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// BybitBaseURL is the Bybit v5 public API
+const BybitBaseURL = "https://api.bybit.com"
+
+// bybitMaxKlines is the maximum "limit" accepted by /v5/market/kline
+const bybitMaxKlines = 1000
+
+// bybitIntervals maps intervals to Bybit kline interval codes
+var bybitIntervals = map[BinanceInterval]string{
+	Interval1m:  "1",
+	Interval5m:  "5",
+	Interval15m: "15",
+	Interval30m: "30",
+	Interval1h:  "60",
+	Interval4h:  "240",
+	Interval1d:  "D",
+	Interval1w:  "W",
+}
+
+// bybitResponse is the v5 response envelope
+type bybitResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// BybitProvider serves Bybit spot candles
+type BybitProvider struct {
+	BaseURL string // overridable for recorded fixtures
+}
+
+// NewBybitProvider creates a provider for the public Bybit API
+func NewBybitProvider() BybitProvider {
+	return BybitProvider{BaseURL: BybitBaseURL}
+}
+
+// Name returns the provider name
+func (BybitProvider) Name() string { return "Bybit" }
+
+// Market returns MarketCrypto
+func (BybitProvider) Market() Market { return MarketCrypto }
+
+// NormalizeSymbol converts input to a Bybit symbol (e.g., "btc/usdt" -> "BTCUSDT")
+func (BybitProvider) NormalizeSymbol(input string) (string, string, error) {
+	pair, err := ParseCryptoPair(input)
+	if err != nil {
+		return "", "", err
+	}
+	return pair.Base + pair.Quote, pair.String(), nil
+}
+
+// get calls a v5 endpoint and returns the result payload
+func (p BybitProvider) get(ctx context.Context, path string, query url.Values) (json.RawMessage, error) {
+	body, _, err := DefaultHTTPClient.Get(ctx, fmt.Sprintf("%s%s?%s", p.BaseURL, path, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Bybit API error: %w", err)
+	}
+	return parseBybitResponse(body)
+}
+
+// parseBybitResponse unwraps the v5 envelope
+// retCode 10001 (invalid/unsupported symbol) is reported as errSymbolNotListed
+func parseBybitResponse(body []byte) (json.RawMessage, error) {
+	var resp bybitResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse Bybit JSON: %w", err)
+	}
+	switch resp.RetCode {
+	case 0:
+		return resp.Result, nil
+	case 10001:
+		return nil, fmt.Errorf("Bybit: %s: %w", resp.RetMsg, errSymbolNotListed)
+	default:
+		return nil, fmt.Errorf("Bybit error %d: %s", resp.RetCode, resp.RetMsg)
+	}
+}
+
+// FetchCandles fetches spot klines, paginating backwards with "end"
+func (p BybitProvider) FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	code, ok := bybitIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("Bybit does not support interval %s", interval)
+	}
+	if limit < 1 {
+		limit = 200
+	}
+
+	return fetchCandlePages(ctx, limit, bybitMaxKlines, func(before time.Time, n int) ([]Candlestick, error) {
+		query := url.Values{}
+		query.Set("category", "spot")
+		query.Set("symbol", symbol)
+		query.Set("interval", code)
+		query.Set("limit", strconv.Itoa(n))
+		if !before.IsZero() {
+			query.Set("end", strconv.FormatInt(before.UnixMilli()-1, 10))
+		}
+		result, err := p.get(ctx, "/v5/market/kline", query)
+		if err != nil {
+			return nil, err
+		}
+		return parseBybitKlines(result, interval)
+	})
+}
+
+// parseBybitKlines parses result.list: [startTime, open, high, low, close, volume, turnover], newest first
+func parseBybitKlines(result json.RawMessage, interval BinanceInterval) ([]Candlestick, error) {
+	var raw struct {
+		List [][]string `json:"list"`
+	}
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Bybit klines: %w", err)
+	}
+
+	candles := make([]Candlestick, 0, len(raw.List))
+	for _, item := range raw.List {
+		if len(item) < 6 {
+			continue
+		}
+		start, _ := strconv.ParseInt(item[0], 10, 64)
+		open, _ := strconv.ParseFloat(item[1], 64)
+		high, _ := strconv.ParseFloat(item[2], 64)
+		low, _ := strconv.ParseFloat(item[3], 64)
+		close, _ := strconv.ParseFloat(item[4], 64)
+		volume, _ := strconv.ParseFloat(item[5], 64)
+
+		candle := exchangeCandle(time.UnixMilli(start), interval, open, high, low, close, volume)
+		if len(item) >= 7 {
+			candle.QuoteVolume, _ = strconv.ParseFloat(item[6], 64)
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// GetCurrentPrice returns the last traded price from /v5/market/tickers
+func (p BybitProvider) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	query := url.Values{}
+	query.Set("category", "spot")
+	query.Set("symbol", symbol)
+	result, err := p.get(ctx, "/v5/market/tickers", query)
+	if err != nil {
+		return 0, err
+	}
+	return parseBybitTicker(result, symbol)
+}
+
+// parseBybitTicker reads lastPrice from result.list[0]
+func parseBybitTicker(result json.RawMessage, symbol string) (float64, error) {
+	var raw struct {
+		List []struct {
+			LastPrice string `json:"lastPrice"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return 0, fmt.Errorf("failed to parse Bybit ticker: %w", err)
+	}
+	if len(raw.List) == 0 {
+		return 0, fmt.Errorf("Bybit: no ticker for %s: %w", symbol, errSymbolNotListed)
+	}
+	return strconv.ParseFloat(raw.List[0].LastPrice, 64)
+}
+
+// ValidateSymbol checks the symbol through its ticker
+func (p BybitProvider) ValidateSymbol(ctx context.Context, symbol string) (bool, error) {
+	return validateByPrice(ctx, p, symbol)
+}
+
+// SupportedIntervals returns the intervals with a native Bybit kline
+func (BybitProvider) SupportedIntervals() []BinanceInterval {
+	return []BinanceInterval{Interval1m, Interval5m, Interval15m, Interval30m, Interval1h, Interval4h, Interval1d, Interval1w}
+}
+
+// Precision borrows the Binance tick size of the same pair
+func (BybitProvider) Precision(symbol string) InstrumentPrecision {
+	pair, _ := ParseCryptoPair(symbol)
+	return cryptoPairPrecision(pair)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCandleCacheDir is used when CANDLE_CACHE_DIR is not set
+const DefaultCandleCacheDir = ".cache/candles"
+
+// maxCachedCandles caps the candles kept per provider/symbol/interval file
+const maxCachedCandles = 5000
+
+// cacheFormatVersion is bumped when Candlestick gains fields; older files are refetched
+const cacheFormatVersion = 2
+
+// CacheTTL returns how long cached candles for an interval are served without
+// asking the provider for the missing tail
+func CacheTTL(interval BinanceInterval) time.Duration {
+	switch interval {
+	case Interval1m:
+		return 15 * time.Second
+	case Interval5m:
+		return 1 * time.Minute
+	case Interval15m:
+		return 2 * time.Minute
+	case Interval30m, Interval1h:
+		return 5 * time.Minute
+	case Interval4h:
+		return 15 * time.Minute
+	case Interval1d:
+		return 1 * time.Hour
+	case Interval1w:
+		return 6 * time.Hour
+	default:
+		return 1 * time.Minute
+	}
+}
+
+// cacheEntry is the on-disk format of one cache file
+type cacheEntry struct {
+	Version   int           `json:"version"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Candles   []Candlestick `json:"candles"`
+}
+
+// CandleCache is a persistent candle store keyed by provider/symbol/interval
+type CandleCache struct {
+	dir   string
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewCandleCache creates a cache rooted at dir
+func NewCandleCache(dir string) *CandleCache {
+	return &CandleCache{
+		dir:   dir,
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// DefaultCandleCache is the cache shared by the registered providers
+var DefaultCandleCache = NewCandleCache(candleCacheDir())
+
+func candleCacheDir() string {
+	if dir := os.Getenv("CANDLE_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultCandleCacheDir
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._=-]`)
+
+// path returns the cache file for a key
+func (cc *CandleCache) path(provider, symbol string, interval BinanceInterval) string {
+	return filepath.Join(cc.dir,
+		unsafePathChars.ReplaceAllString(strings.ToLower(provider), "_"),
+		fmt.Sprintf("%s_%s.json", unsafePathChars.ReplaceAllString(symbol, "_"), interval))
+}
+
+// lock returns the mutex serializing access to one cache file
+func (cc *CandleCache) lock(key string) *sync.Mutex {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	l, ok := cc.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		cc.locks[key] = l
+	}
+	return l
+}
+
+// load reads a cache file (missing or corrupt files yield an empty entry)
+func (cc *CandleCache) load(path string) cacheEntry {
+	var entry cacheEntry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		log.Printf("⚠️ [CACHE] Ignoring corrupt cache file %s: %v", path, err)
+		return cacheEntry{}
+	}
+	if entry.Version != cacheFormatVersion {
+		return cacheEntry{}
+	}
+	return entry
+}
+
+// save writes a cache file atomically
+func (cc *CandleCache) save(path string, entry cacheEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Fetch returns the latest `limit` candles, serving closed candles from disk
+// and only fetching the missing tail from the provider once the TTL expires
+func (cc *CandleCache) Fetch(ctx context.Context, p MarketDataProvider, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	path := cc.path(p.Name(), symbol, interval)
+	l := cc.lock(path)
+	l.Lock()
+	defer l.Unlock()
+
+	entry := cc.load(path)
+	now := time.Now()
+
+	// Fresh, deep enough and the cached forming candle has not closed yet: serve from disk
+	// Past a bar boundary the tail is refetched so the new bar is not missing for a whole TTL
+	if len(entry.Candles) >= limit && now.Sub(entry.UpdatedAt) < CacheTTL(interval) &&
+		entry.Candles[len(entry.Candles)-1].CloseTime.After(now) {
+		return lastCandles(entry.Candles, limit), nil
+	}
+
+	// Keep only closed candles; the forming one is always refetched
+	closed := entry.Candles
+	for len(closed) > 0 && !closed[len(closed)-1].CloseTime.Before(now) {
+		closed = closed[:len(closed)-1]
+	}
+
+	// Work out how much of the tail is missing
+	fetchLimit := limit
+	if len(closed) > 0 {
+		elapsed := now.Sub(closed[len(closed)-1].OpenTime)
+		missing := int(elapsed/IntervalDuration(interval)) + 1 // includes one candle of overlap
+		if missing < fetchLimit && len(closed)+missing-1 >= limit {
+			fetchLimit = missing
+		}
+	}
+
+	fresh, err := p.FetchCandles(ctx, symbol, interval, fetchLimit)
+	if err != nil {
+		// Stale data is better than nothing when the upstream is down
+		if len(entry.Candles) > 0 {
+			log.Printf("⚠️ [CACHE] %s %s %s refresh failed, serving stale data: %v", p.Name(), symbol, interval, err)
+			return lastCandles(entry.Candles, limit), nil
+		}
+		return nil, err
+	}
+
+	// Only stitch when the fetched tail overlaps or directly follows the cache
+	merged := fresh
+	if fetchLimit < limit && len(fresh) > 0 && !fresh[0].OpenTime.After(closed[len(closed)-1].OpenTime.Add(IntervalDuration(interval))) {
+		merged = mergeCandles(closed, fresh)
+	}
+	merged = lastCandles(merged, maxCachedCandles)
+
+	if err := cc.save(path, cacheEntry{Version: cacheFormatVersion, UpdatedAt: now, Candles: merged}); err != nil {
+		log.Printf("⚠️ [CACHE] Failed to write %s: %v", path, err)
+	}
+
+	return lastCandles(merged, limit), nil
+}
+
+// mergeCandles combines two candle series, newer values winning on equal open time
+func mergeCandles(older, newer []Candlestick) []Candlestick {
+	byTime := make(map[int64]Candlestick, len(older)+len(newer))
+	for _, c := range older {
+		byTime[c.OpenTime.UnixMilli()] = c
+	}
+	for _, c := range newer {
+		byTime[c.OpenTime.UnixMilli()] = c
+	}
+
+	merged := make([]Candlestick, 0, len(byTime))
+	for _, c := range byTime {
+		merged = append(merged, c)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].OpenTime.Before(merged[j].OpenTime)
+	})
+	return merged
+}
+
+// lastCandles returns at most n candles from the end of the series
+func lastCandles(candles []Candlestick, n int) []Candlestick {
+	if len(candles) > n {
+		return candles[len(candles)-n:]
+	}
+	return candles
+}
+
+// CachedProvider wraps a MarketDataProvider with a CandleCache
+type CachedProvider struct {
+	MarketDataProvider
+	cache *CandleCache
+}
+
+// NewCachedProvider wraps p so FetchCandles goes through cache
+func NewCachedProvider(p MarketDataProvider, cache *CandleCache) CachedProvider {
+	return CachedProvider{MarketDataProvider: p, cache: cache}
+}
+
+// FetchCandles serves candles through the cache
+func (cp CachedProvider) FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	if limit < 1 {
+		limit = 200
+	}
+	return cp.cache.Fetch(ctx, cp.MarketDataProvider, symbol, interval, limit)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"image"
 	"image/color"
@@ -26,6 +27,10 @@ type ChartConfig struct {
 	ShowMA      bool
 	MAperiods   []int
 	DarkMode    bool
+	Precision   *InstrumentPrecision // Price label precision (nil = guess from magnitude)
+
+	ShowVolumeProfile bool // Volume-at-price histogram of the visible candles on the right
+	VolumeProfileBins int
 }
 
 // DefaultChartConfig returns a sensible default configuration
@@ -40,6 +45,9 @@ func DefaultChartConfig() ChartConfig {
 		ShowMA:      true,
 		MAperiods:   []int{20, 50},
 		DarkMode:    true,
+
+		ShowVolumeProfile: true,
+		VolumeProfileBins: vpDefaultBins,
 	}
 }
 
@@ -127,6 +135,13 @@ func GenerateCandlestickChart(candles []Candlestick, symbol string, interval Bin
 		candles = candles[len(candles)-maxCandles:]
 	}
 
+	// Draw volume profile behind the candles
+	if config.ShowVolumeProfile {
+		if vp := ComputeVolumeProfile(candles, config.VolumeProfileBins); vp != nil {
+			drawVolumeProfile(img, vp, chartRight, chartTop, chartWidth, chartHeight, maxPrice, priceRange)
+		}
+	}
+
 	// Draw candles
 	for i, c := range candles {
 		x := chartLeft + i*totalCandleWidth + config.CandleGap/2
@@ -175,8 +190,9 @@ func GenerateCandlestickChart(candles []Candlestick, symbol string, interval Bin
 
 	// Draw Moving Averages
 	if config.ShowMA && len(candles) > 50 {
-		ma20 := calculateMA(candles, 20)
-		ma50 := calculateMA(candles, 50)
+		closes := Closes(candles)
+		ma20 := SMA(closes, 20)
+		ma50 := SMA(closes, 50)
 
 		drawMALine(img, ma20, candles, chartLeft, chartTop, totalCandleWidth, maxPrice, priceRange, float64(chartHeight), colorMA20)
 		drawMALine(img, ma50, candles, chartLeft, chartTop, totalCandleWidth, maxPrice, priceRange, float64(chartHeight), colorMA50)
@@ -187,7 +203,7 @@ func GenerateCandlestickChart(candles []Candlestick, symbol string, interval Bin
 	if !config.DarkMode {
 		textColor = colorTextLight
 	}
-	drawPriceScale(img, chartRight+5, chartTop, chartBottom, minPrice, maxPrice, textColor)
+	drawPriceScale(img, chartRight+5, chartTop, chartBottom, minPrice, maxPrice, textColor, config.formatPrice)
 
 	// Draw title
 	title := fmt.Sprintf("%s - %s", symbol, GetTimeframeName(interval))
@@ -195,7 +211,7 @@ func GenerateCandlestickChart(candles []Candlestick, symbol string, interval Bin
 
 	// Draw current price
 	lastCandle := candles[len(candles)-1]
-	priceStr := formatPrice(lastCandle.Close)
+	priceStr := config.formatPrice(lastCandle.Close)
 	priceColor := colorBullish
 	if lastCandle.Close < lastCandle.Open {
 		priceColor = colorBearish
@@ -254,26 +270,10 @@ func drawHorizontalGridLines(img *image.RGBA, x1, x2, y1, y2, count int, c color
 	}
 }
 
-func calculateMA(candles []Candlestick, period int) []float64 {
-	ma := make([]float64, len(candles))
-	for i := range candles {
-		if i < period-1 {
-			ma[i] = 0
-			continue
-		}
-		sum := 0.0
-		for j := 0; j < period; j++ {
-			sum += candles[i-j].Close
-		}
-		ma[i] = sum / float64(period)
-	}
-	return ma
-}
-
 func drawMALine(img *image.RGBA, ma []float64, candles []Candlestick, chartLeft, chartTop, totalCandleWidth int, maxPrice, priceRange, chartHeight float64, c color.Color) {
 	prevX, prevY := 0, 0
 	for i, val := range ma {
-		if val == 0 {
+		if math.IsNaN(val) {
 			continue
 		}
 		x := chartLeft + i*totalCandleWidth + totalCandleWidth/2
@@ -335,7 +335,7 @@ func drawText(img *image.RGBA, x, y int, text string, c color.Color) {
 	d.DrawString(text)
 }
 
-func drawPriceScale(img *image.RGBA, x, top, bottom int, minPrice, maxPrice float64, c color.Color) {
+func drawPriceScale(img *image.RGBA, x, top, bottom int, minPrice, maxPrice float64, c color.Color, format func(float64) string) {
 	steps := 5
 	priceStep := (maxPrice - minPrice) / float64(steps)
 	yStep := (bottom - top) / steps
@@ -343,11 +343,20 @@ func drawPriceScale(img *image.RGBA, x, top, bottom int, minPrice, maxPrice floa
 	for i := 0; i <= steps; i++ {
 		price := maxPrice - float64(i)*priceStep
 		y := top + i*yStep
-		priceStr := formatPrice(price)
+		priceStr := format(price)
 		drawText(img, x, y+4, priceStr, c)
 	}
 }
 
+// formatPrice formats a price with the configured precision
+func (config ChartConfig) formatPrice(price float64) string {
+	if config.Precision != nil {
+		return config.Precision.Format(price)
+	}
+	return formatPrice(price)
+}
+
+// formatPrice guesses display decimals from the price magnitude
 func formatPrice(price float64) string {
 	if price >= 1000 {
 		return fmt.Sprintf("%.2f", price)
@@ -360,14 +369,16 @@ func formatPrice(price float64) string {
 }
 
 // GenerateMultiTimeframeCharts generates charts for all timeframes
-func GenerateMultiTimeframeCharts(symbol string, mode TradingMode, candleLimit int) ([]ChartData, error) {
+func GenerateMultiTimeframeCharts(ctx context.Context, symbol string, mode TradingMode, candleLimit int) ([]ChartData, error) {
 	timeframes := GetTimeframesForMode(mode)
 	charts := make([]ChartData, 0, len(timeframes))
 
 	config := DefaultChartConfig()
+	prec := BinancePrecision(symbol)
+	config.Precision = &prec
 
 	for _, tf := range timeframes {
-		candles, err := FetchCandlesticks(symbol, tf, candleLimit)
+		candles, err := FetchCandlesticks(ctx, symbol, tf, candleLimit)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch %s data: %w", tf, err)
 		}
@@ -400,51 +411,18 @@ func CalculateTechnicalSummary(candles []Candlestick) string {
 		return "Insufficient data"
 	}
 
-	last := candles[len(candles)-1]
-	ma20 := calculateMA(candles, 20)
-	ma50 := calculateMA(candles, 50)
+	closes := Closes(candles)
 
-	lastMA20 := ma20[len(ma20)-1]
-	lastMA50 := ma50[len(ma50)-1]
-
-	// Calculate trend
-	trend := "NEUTRAL"
-	if last.Close > lastMA20 && lastMA20 > lastMA50 {
-		trend = "BULLISH"
-	} else if last.Close < lastMA20 && lastMA20 < lastMA50 {
-		trend = "BEARISH"
+	// Trend and volatility from the regime classifier
+	trend, volatility := "NEUTRAL", "LOW"
+	if regime := ClassifyRegime(candles); regime != nil {
+		trend = fmt.Sprintf("%s (%s %d/100)", regime.Trend(), regime.State, regime.Strength)
+		volatility = regime.Volatility
 	}
 
-	// Calculate volatility (ATR-like)
-	var atrSum float64
-	for i := len(candles) - 14; i < len(candles); i++ {
-		atrSum += candles[i].High - candles[i].Low
-	}
-	atr := atrSum / 14
-	volatility := "LOW"
-	avgPrice := last.Close
-	if atr/avgPrice > 0.02 {
-		volatility = "HIGH"
-	} else if atr/avgPrice > 0.01 {
-		volatility = "MEDIUM"
-	}
+	// Calculate momentum (Wilder RSI)
+	rsi := Last(RSI(closes, rsiPeriod))
 
-	// Calculate momentum (simple RSI approximation)
-	gains, losses := 0.0, 0.0
-	for i := len(candles) - 14; i < len(candles); i++ {
-		change := candles[i].Close - candles[i].Open
-		if change > 0 {
-			gains += change
-		} else {
-			losses += math.Abs(change)
-		}
-	}
-	rs := 1.0
-	if losses > 0 {
-		rs = gains / losses
-	}
-	rsi := 100 - (100 / (1 + rs))
-	
 	momentum := "NEUTRAL"
 	if rsi > 70 {
 		momentum = "OVERBOUGHT"
@@ -476,7 +454,9 @@ var (
 )
 
 // GenerateChartWithLevels creates a chart with Entry/SL/TP levels marked
-func GenerateChartWithLevels(candles []Candlestick, symbol string, interval BinanceInterval, levels *TradeLevels) ([]byte, error) {
+// book is optional; when set, order book walls are drawn as horizontal bands
+// annotations (SMC detections, AI zones) are drawn over the candles and clipped to the plot area
+func GenerateChartWithLevels(candles []Candlestick, symbol string, interval BinanceInterval, levels *TradeLevels, book *OrderBookSummary, annotations []ChartAnnotation, prec InstrumentPrecision) ([]byte, error) {
 	if len(candles) == 0 {
 		return nil, fmt.Errorf("no candle data to render")
 	}
@@ -484,6 +464,7 @@ func GenerateChartWithLevels(candles []Candlestick, symbol string, interval Bina
 	config := DefaultChartConfig()
 	config.Width = 1400
 	config.Height = 700
+	config.Precision = &prec
 
 	// Create image
 	img := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
@@ -548,6 +529,11 @@ func GenerateChartWithLevels(candles []Candlestick, symbol string, interval Bina
 	// Draw grid lines
 	drawHorizontalGridLines(img, chartLeft, chartRight, chartTop, chartBottom, 5, colorGridDark)
 
+	// Draw order book walls behind the candles
+	if book != nil {
+		drawDepthWalls(img, book, chartLeft, chartRight, chartTop, chartHeight, maxPrice, priceRange, config.formatPrice)
+	}
+
 	// Calculate candle positions
 	totalCandleWidth := config.CandleWidth + config.CandleGap
 	maxCandles := chartWidth / totalCandleWidth
@@ -555,6 +541,15 @@ func GenerateChartWithLevels(candles []Candlestick, symbol string, interval Bina
 		candles = candles[len(candles)-maxCandles:]
 	}
 
+	// Draw volume profile behind the candles
+	drewVolumeProfile := false
+	if config.ShowVolumeProfile {
+		if vp := ComputeVolumeProfile(candles, config.VolumeProfileBins); vp != nil {
+			drawVolumeProfile(img, vp, chartRight, chartTop, chartWidth, chartHeight, maxPrice, priceRange)
+			drewVolumeProfile = true
+		}
+	}
+
 	// Draw candles
 	for i, c := range candles {
 		x := chartLeft + i*totalCandleWidth + config.CandleGap/2
@@ -603,52 +598,68 @@ func GenerateChartWithLevels(candles []Candlestick, symbol string, interval Bina
 
 	// Draw Moving Averages
 	if config.ShowMA && len(candles) > 50 {
-		ma20 := calculateMA(candles, 20)
-		ma50 := calculateMA(candles, 50)
+		closes := Closes(candles)
+		ma20 := SMA(closes, 20)
+		ma50 := SMA(closes, 50)
 		drawMALine(img, ma20, candles, chartLeft, chartTop, totalCandleWidth, maxPrice, priceRange, float64(chartHeight), colorMA20)
 		drawMALine(img, ma50, candles, chartLeft, chartTop, totalCandleWidth, maxPrice, priceRange, float64(chartHeight), colorMA50)
 	}
 
+	// Draw zones, structure and liquidity
+	if len(annotations) > 0 {
+		layout := chartLayout{
+			candles:          candles,
+			left:             chartLeft,
+			right:            chartRight,
+			top:              chartTop,
+			height:           chartHeight,
+			totalCandleWidth: totalCandleWidth,
+			maxPrice:         maxPrice,
+			priceRange:       priceRange,
+		}
+		drawAnnotations(img, annotations, layout, config.formatPrice)
+	}
+
 	// Draw Level Lines (Entry, SL, TP)
 	if levels != nil {
 		// Entry Level (Blue)
 		if levels.Entry > 0 {
 			entryY := chartTop + int((maxPrice-levels.Entry)/priceRange*float64(chartHeight))
 			drawHorizontalLevelLine(img, chartLeft, chartRight, entryY, colorEntry)
-			drawText(img, chartRight+5, entryY+4, fmt.Sprintf("ENTRY %.2f", levels.Entry), colorEntry)
+			drawText(img, chartRight+5, entryY+4, fmt.Sprintf("ENTRY %s", prec.Format(levels.Entry)), colorEntry)
 		}
 
 		// Stoploss Level (Red)
 		if levels.SL > 0 {
 			slY := chartTop + int((maxPrice-levels.SL)/priceRange*float64(chartHeight))
 			drawHorizontalLevelLine(img, chartLeft, chartRight, slY, colorSL)
-			drawText(img, chartRight+5, slY+4, fmt.Sprintf("SL %.2f", levels.SL), colorSL)
+			drawText(img, chartRight+5, slY+4, fmt.Sprintf("SL %s", prec.Format(levels.SL)), colorSL)
 		}
 
 		// TP1 Level (Green)
 		if levels.TP1 > 0 {
 			tp1Y := chartTop + int((maxPrice-levels.TP1)/priceRange*float64(chartHeight))
 			drawHorizontalLevelLine(img, chartLeft, chartRight, tp1Y, colorTP)
-			drawText(img, chartRight+5, tp1Y+4, fmt.Sprintf("TP1 %.2f", levels.TP1), colorTP)
+			drawText(img, chartRight+5, tp1Y+4, fmt.Sprintf("TP1 %s", prec.Format(levels.TP1)), colorTP)
 		}
 
 		// TP2 Level (Green)
 		if levels.TP2 > 0 {
 			tp2Y := chartTop + int((maxPrice-levels.TP2)/priceRange*float64(chartHeight))
 			drawHorizontalLevelLine(img, chartLeft, chartRight, tp2Y, colorTP)
-			drawText(img, chartRight+5, tp2Y+4, fmt.Sprintf("TP2 %.2f", levels.TP2), colorTP)
+			drawText(img, chartRight+5, tp2Y+4, fmt.Sprintf("TP2 %s", prec.Format(levels.TP2)), colorTP)
 		}
 
 		// TP3 Level (Green)
 		if levels.TP3 > 0 {
 			tp3Y := chartTop + int((maxPrice-levels.TP3)/priceRange*float64(chartHeight))
 			drawHorizontalLevelLine(img, chartLeft, chartRight, tp3Y, colorTP)
-			drawText(img, chartRight+5, tp3Y+4, fmt.Sprintf("TP3 %.2f", levels.TP3), colorTP)
+			drawText(img, chartRight+5, tp3Y+4, fmt.Sprintf("TP3 %s", prec.Format(levels.TP3)), colorTP)
 		}
 	}
 
 	// Draw price scale
-	drawPriceScale(img, chartRight+5, chartTop, chartBottom, minPrice, maxPrice, colorTextDark)
+	drawPriceScale(img, chartRight+5, chartTop, chartBottom, minPrice, maxPrice, colorTextDark, config.formatPrice)
 
 	// Draw title
 	title := fmt.Sprintf("%s - %s | ENTRY CHART", symbol, GetTimeframeName(interval))
@@ -656,7 +667,7 @@ func GenerateChartWithLevels(candles []Candlestick, symbol string, interval Bina
 
 	// Draw current price
 	lastCandle := candles[len(candles)-1]
-	priceStr := formatPrice(lastCandle.Close)
+	priceStr := config.formatPrice(lastCandle.Close)
 	priceColor := colorBullish
 	if lastCandle.Close < lastCandle.Open {
 		priceColor = colorBearish
@@ -668,7 +679,17 @@ func GenerateChartWithLevels(candles []Candlestick, symbol string, interval Bina
 	drawText(img, chartRight-150, 20, timestamp, colorTextDark)
 
 	// Draw legend
-	drawText(img, chartLeft, chartBottom+50, "🔵 Entry  🔴 Stoploss  🟢 Take Profit  🟡 MA20  🟣 MA50", colorTextDark)
+	legend := "🔵 Entry  🔴 Stoploss  🟢 Take Profit  🟡 MA20  🟣 MA50"
+	if book != nil {
+		legend += "  ▬ Bid/Ask Walls"
+	}
+	if len(annotations) > 0 {
+		legend += "  [ ] OB/FVG/Zones  -- BOS/CHoCH/EQH/EQL"
+	}
+	if drewVolumeProfile {
+		legend += "  | Volume Profile (POC)"
+	}
+	drawText(img, chartLeft, chartBottom+50, legend, colorTextDark)
 
 	// Encode to PNG
 	var buf bytes.Buffer
@@ -687,3 +708,40 @@ func drawHorizontalLevelLine(img *image.RGBA, x1, x2, y int, c color.Color) {
 		img.Set(x, y-1, c)
 	}
 }
+
+// Colors for order book wall bands
+var (
+	colorBidWall = color.RGBA{R: 38, G: 166, B: 91, A: 70}
+	colorAskWall = color.RGBA{R: 231, G: 76, B: 60, A: 70}
+)
+
+// drawDepthWalls draws translucent bands at order book wall prices inside the visible range
+// Band thickness scales with wall size relative to the largest wall
+func drawDepthWalls(img *image.RGBA, book *OrderBookSummary, chartLeft, chartRight, chartTop, chartHeight int, maxPrice, priceRange float64, format func(float64) string) {
+	largest := 0.0
+	for _, w := range append(append([]DepthWall{}, book.BidWalls...), book.AskWalls...) {
+		if w.Notional > largest {
+			largest = w.Notional
+		}
+	}
+	if largest == 0 {
+		return
+	}
+
+	drawWalls := func(walls []DepthWall, c color.RGBA, label string) {
+		for _, w := range walls {
+			if w.Price > maxPrice || w.Price < maxPrice-priceRange {
+				continue
+			}
+			y := chartTop + int((maxPrice-w.Price)/priceRange*float64(chartHeight))
+			half := 2 + int(6*w.Notional/largest)
+			band := image.Rect(chartLeft, y-half, chartRight, y+half)
+			draw.Draw(img, band, &image.Uniform{c}, image.Point{}, draw.Over)
+			textColor := c
+			textColor.A = 255
+			drawText(img, chartLeft+5, y-half-2, fmt.Sprintf("%s %s", label, format(w.Price)), textColor)
+		}
+	}
+	drawWalls(book.BidWalls, colorBidWall, "BID WALL")
+	drawWalls(book.AskWalls, colorAskWall, "ASK WALL")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CoinbaseBaseURL is the Coinbase Exchange public API
+const CoinbaseBaseURL = "https://api.exchange.coinbase.com"
+
+// coinbaseMaxCandles is the maximum number of candles per /candles request
+const coinbaseMaxCandles = 300
+
+// coinbaseGranularities maps intervals to candle granularities in seconds
+// 30m, 4h and 1w are not offered and get resampled by FetchCandlesWithResample
+var coinbaseGranularities = map[BinanceInterval]int{
+	Interval1m:  60,
+	Interval5m:  300,
+	Interval15m: 900,
+	Interval1h:  3600,
+	Interval1d:  86400,
+}
+
+// CoinbaseProvider serves Coinbase Exchange candles
+type CoinbaseProvider struct {
+	BaseURL string // overridable for recorded fixtures
+}
+
+// NewCoinbaseProvider creates a provider for the public Coinbase Exchange API
+func NewCoinbaseProvider() CoinbaseProvider {
+	return CoinbaseProvider{BaseURL: CoinbaseBaseURL}
+}
+
+// Name returns the provider name
+func (CoinbaseProvider) Name() string { return "Coinbase" }
+
+// Market returns MarketCrypto
+func (CoinbaseProvider) Market() Market { return MarketCrypto }
+
+// NormalizeSymbol converts input to a Coinbase product ID (e.g., "btcusd" -> "BTC-USD")
+func (CoinbaseProvider) NormalizeSymbol(input string) (string, string, error) {
+	pair, err := ParseCryptoPair(input)
+	if err != nil {
+		return "", "", err
+	}
+	return pair.Base + "-" + pair.Quote, pair.String(), nil
+}
+
+// coinbaseHeaders returns the headers Coinbase requires (requests without User-Agent are rejected)
+func coinbaseHeaders() http.Header {
+	return http.Header{"User-Agent": []string{browserUserAgent}}
+}
+
+// get calls a product endpoint; unknown products (404) are reported as errSymbolNotListed
+func (p CoinbaseProvider) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	endpoint := p.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	body, _, err := DefaultHTTPClient.Get(ctx, endpoint, coinbaseHeaders())
+	if IsHTTPStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("Coinbase: %w", errSymbolNotListed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Coinbase API error: %w", err)
+	}
+	return body, nil
+}
+
+// FetchCandles fetches candles, paginating backwards with start/end windows
+func (p CoinbaseProvider) FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	granularity, ok := coinbaseGranularities[interval]
+	if !ok {
+		return nil, fmt.Errorf("Coinbase does not support interval %s", interval)
+	}
+	if limit < 1 {
+		limit = 200
+	}
+	step := time.Duration(granularity) * time.Second
+
+	return fetchCandlePages(ctx, limit, coinbaseMaxCandles, func(before time.Time, n int) ([]Candlestick, error) {
+		query := url.Values{}
+		query.Set("granularity", strconv.Itoa(granularity))
+		if !before.IsZero() {
+			end := before.Add(-time.Second)
+			query.Set("start", end.Add(-time.Duration(n)*step).UTC().Format(time.RFC3339))
+			query.Set("end", end.UTC().Format(time.RFC3339))
+		}
+		body, err := p.get(ctx, "/products/"+url.PathEscape(symbol)+"/candles", query)
+		if err != nil {
+			return nil, err
+		}
+		return parseCoinbaseCandles(body, interval)
+	})
+}
+
+// parseCoinbaseCandles parses [time, low, high, open, close, volume] arrays, newest first
+func parseCoinbaseCandles(body []byte, interval BinanceInterval) ([]Candlestick, error) {
+	var raw [][]float64
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Coinbase candles: %w", err)
+	}
+
+	candles := make([]Candlestick, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 6 {
+			continue
+		}
+		candles = append(candles, exchangeCandle(time.Unix(int64(item[0]), 0), interval, item[3], item[2], item[1], item[4], item[5]))
+	}
+	return candles, nil
+}
+
+// GetCurrentPrice returns the last trade price from /products/{id}/ticker
+func (p CoinbaseProvider) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	body, err := p.get(ctx, "/products/"+url.PathEscape(symbol)+"/ticker", nil)
+	if err != nil {
+		return 0, err
+	}
+	return parseCoinbaseTicker(body)
+}
+
+// parseCoinbaseTicker reads "price" from a ticker response
+func parseCoinbaseTicker(body []byte) (float64, error) {
+	var raw struct {
+		Price string `json:"price"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return 0, fmt.Errorf("failed to parse Coinbase ticker: %w", err)
+	}
+	return strconv.ParseFloat(raw.Price, 64)
+}
+
+// ValidateSymbol checks the product through its ticker
+func (p CoinbaseProvider) ValidateSymbol(ctx context.Context, symbol string) (bool, error) {
+	return validateByPrice(ctx, p, symbol)
+}
+
+// SupportedIntervals returns the native Coinbase granularities
+func (CoinbaseProvider) SupportedIntervals() []BinanceInterval {
+	return []BinanceInterval{Interval1m, Interval5m, Interval15m, Interval1h, Interval1d}
+}
+
+// Precision borrows the Binance tick size of the same pair
+func (CoinbaseProvider) Precision(symbol string) InstrumentPrecision {
+	pair, _ := ParseCryptoPair(symbol)
+	return cryptoPairPrecision(pair)
+}
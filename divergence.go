@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Regular and hidden divergences between price swings and RSI, MACD and OBV
+
+const (
+	divMinBars     = 5  // swings closer than this are the same move
+	divMaxBars     = 60 // swings further apart than this are unrelated
+	divRecentBars  = 30 // the later swing must be within this many bars of the last candle
+	divMaxReported = 4
+)
+
+// Divergence types
+const (
+	DivergenceRegular = "REGULAR" // price makes a new extreme the indicator does not confirm (reversal)
+	DivergenceHidden  = "HIDDEN"  // indicator makes the new extreme price does not (continuation)
+)
+
+// Colors for divergence lines
+var (
+	colorDivBullish = color.NRGBA{R: 0, G: 230, B: 118, A: 255}
+	colorDivBearish = color.NRGBA{R: 255, G: 82, B: 82, A: 255}
+)
+
+// Divergence is a disagreement between two consecutive swing highs (or lows) and an indicator
+type Divergence struct {
+	Indicator string // RSI, MACD, OBV
+	Type      string // REGULAR or HIDDEN
+	Direction string // BULLISH (swing lows) or BEARISH (swing highs)
+	From      SwingPoint
+	To        SwingPoint
+	FromValue float64 // indicator at the first swing
+	ToValue   float64 // indicator at the second swing
+}
+
+// DetectDivergences compares consecutive swing highs and lows with RSI(14), the MACD line
+// and OBV, returning the divergences whose later swing is recent, most recent first
+func DetectDivergences(candles []Candlestick, swings []SwingPoint) []Divergence {
+	closes := Closes(candles)
+	indicators := []struct {
+		name   string
+		series []float64
+	}{
+		{"RSI", RSI(closes, rsiPeriod)},
+		{"MACD", MACD(closes, macdFast, macdSlow, macdSignal).MACD},
+		{"OBV", OBV(candles)},
+	}
+
+	var found []Divergence
+	for _, high := range []bool{true, false} {
+		var prev *SwingPoint
+		for k := range swings {
+			s := swings[k]
+			if s.High != high {
+				continue
+			}
+			if prev != nil && s.Index-prev.Index >= divMinBars && s.Index-prev.Index <= divMaxBars &&
+				s.Index >= len(candles)-divRecentBars {
+				for _, ind := range indicators {
+					if d, ok := compareSwings(ind.name, ind.series, *prev, s); ok {
+						found = append(found, d)
+					}
+				}
+			}
+			prev = &swings[k]
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].To.Index > found[j].To.Index })
+	if len(found) > divMaxReported {
+		found = found[:divMaxReported]
+	}
+	return found
+}
+
+// compareSwings classifies the divergence between two swings of the same kind, if any
+func compareSwings(name string, series []float64, a, b SwingPoint) (Divergence, bool) {
+	va, vb := series[a.Index], series[b.Index]
+	if math.IsNaN(va) || math.IsNaN(vb) || va == vb || a.Price == b.Price {
+		return Divergence{}, false
+	}
+
+	d := Divergence{Indicator: name, From: a, To: b, FromValue: va, ToValue: vb}
+	priceUp, indicatorUp := b.Price > a.Price, vb > va
+	if priceUp == indicatorUp {
+		return Divergence{}, false
+	}
+
+	if a.High {
+		d.Direction = SMCBearish
+		d.Type = DivergenceRegular // higher high, lower indicator high
+		if !priceUp {
+			d.Type = DivergenceHidden // lower high, higher indicator high
+		}
+	} else {
+		d.Direction = SMCBullish
+		d.Type = DivergenceRegular // lower low, higher indicator low
+		if priceUp {
+			d.Type = DivergenceHidden // higher low, lower indicator low
+		}
+	}
+	return d, true
+}
+
+// FormatDivergencesForAI formats divergences as a line of a timeframe section
+func FormatDivergencesForAI(divs []Divergence, prec InstrumentPrecision) string {
+	if len(divs) == 0 {
+		return "Divergences: none\n"
+	}
+	p := prec.Format
+	when := func(s SwingPoint) string { return s.Time.UTC().Format("01-02 15:04") }
+
+	parts := make([]string, len(divs))
+	for i, d := range divs {
+		parts[i] = fmt.Sprintf("%s %s %s (price %s @%s -> %s @%s, %s %s -> %s)",
+			d.Type, d.Direction, d.Indicator, p(d.From.Price), when(d.From), p(d.To.Price), when(d.To),
+			d.Indicator, formatIndicatorValue(d.FromValue), formatIndicatorValue(d.ToValue))
+	}
+	return fmt.Sprintf("Divergences: %s\n", strings.Join(parts, " | "))
+}
+
+// formatIndicatorValue prints oscillator values compactly (RSI, MACD) and large OBV totals without decimals
+func formatIndicatorValue(v float64) string {
+	if math.Abs(v) >= 1000 {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.4g", v)
+}
+
+// DivergenceAnnotations draws each divergence as a line between its swing prices
+// Indicators diverging on the same swings share one line (e.g., "RSI/MACD DIV")
+func DivergenceAnnotations(divs []Divergence) []ChartAnnotation {
+	var out []ChartAnnotation
+	lines := map[[2]int]int{} // swing indexes -> position in out
+	for _, d := range divs {
+		key := [2]int{d.From.Index, d.To.Index}
+		if i, ok := lines[key]; ok {
+			out[i].Label = strings.Replace(out[i].Label, " DIV", "/"+d.Indicator+" DIV", 1)
+			continue
+		}
+
+		c := colorDivBullish
+		if d.Direction == SMCBearish {
+			c = colorDivBearish
+		}
+		label := d.Indicator + " DIV"
+		if d.Type == DivergenceHidden {
+			label = "H " + label
+		}
+		lines[key] = len(out)
+		out = append(out, ChartAnnotation{Kind: AnnotationTrendLine, Label: label, Source: SourceAlgo,
+			From: d.From.Time, To: d.To.Time, Price: d.From.Price, EndPrice: d.To.Price, Color: c, Below: !d.From.High})
+	}
+	return out
+}
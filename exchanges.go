@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// exchangePageDelay is the pause between paginated kline requests on non-Binance exchanges
+const exchangePageDelay = 100 * time.Millisecond
+
+// errSymbolNotListed is wrapped by exchange adapters when the exchange does not list a symbol
+var errSymbolNotListed = errors.New("symbol not listed")
+
+// cryptoQuoteAssets are matched as suffixes when splitting compact symbols (BTCUSDT -> BTC/USDT)
+// Longer assets come first so USDT wins over USD
+var cryptoQuoteAssets = []string{"FDUSD", "USDT", "USDC", "BUSD", "TUSD", "USD", "EUR", "GBP", "BTC", "ETH", "BNB"}
+
+// defaultCryptoQuote is used when the user only types a base asset
+const defaultCryptoQuote = "USDT"
+
+// CryptoPair is a base/quote asset pair independent of exchange symbol formats
+type CryptoPair struct {
+	Base  string
+	Quote string
+}
+
+// String returns the pair as "BASE/QUOTE"
+func (p CryptoPair) String() string {
+	return p.Base + "/" + p.Quote
+}
+
+// ParseCryptoPair splits user input such as "btc", "BTCUSDT", "eth/btc" or "SOL-USDC"
+func ParseCryptoPair(input string) (CryptoPair, error) {
+	cleaned := strings.ToUpper(strings.TrimSpace(input))
+	if cleaned == "" {
+		return CryptoPair{}, fmt.Errorf("empty crypto symbol")
+	}
+
+	if i := strings.IndexAny(cleaned, "/-_ "); i > 0 && i < len(cleaned)-1 {
+		return CryptoPair{Base: cleaned[:i], Quote: cleaned[i+1:]}, nil
+	}
+
+	for _, quote := range cryptoQuoteAssets {
+		if base := strings.TrimSuffix(cleaned, quote); base != cleaned && base != "" {
+			return CryptoPair{Base: base, Quote: quote}, nil
+		}
+	}
+	return CryptoPair{Base: cleaned, Quote: defaultCryptoQuote}, nil
+}
+
+// cryptoPairPrecision borrows the Binance tick size of the same pair (or its USDT pair for
+// fiat quotes) since the other exchanges' public APIs are only queried for candles
+func cryptoPairPrecision(pair CryptoPair) InstrumentPrecision {
+	if _, ok := DefaultSymbolCatalog.Get(pair.Base + pair.Quote); ok {
+		return BinancePrecision(pair.Base + pair.Quote)
+	}
+	switch pair.Quote {
+	case "USD", "EUR", "GBP":
+		if _, ok := DefaultSymbolCatalog.Get(pair.Base + "USDT"); ok {
+			return BinancePrecision(pair.Base + "USDT")
+		}
+	}
+	return InstrumentPrecision{Decimals: defaultCryptoDecimals}
+}
+
+// validateByPrice checks a symbol by requesting its ticker price
+// Adapters wrap errSymbolNotListed when the exchange answers that the symbol is unknown
+func validateByPrice(ctx context.Context, p MarketDataProvider, symbol string) (bool, error) {
+	_, err := p.GetCurrentPrice(ctx, symbol)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, errSymbolNotListed) {
+		return false, nil
+	}
+	return false, fmt.Errorf("could not validate %s on %s: %w", symbol, p.Name(), err)
+}
+
+// fetchCandlePages collects the latest limit candles in pages of at most pageSize
+// fetchPage returns up to n candles opening before `before` (zero = latest), in any order
+func fetchCandlePages(ctx context.Context, limit, pageSize int, fetchPage func(before time.Time, n int) ([]Candlestick, error)) ([]Candlestick, error) {
+	var all []Candlestick
+	var before time.Time
+	for len(all) < limit {
+		n := min(pageSize, limit-len(all))
+		page, err := fetchPage(before, n)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Slice(page, func(i, j int) bool { return page[i].OpenTime.Before(page[j].OpenTime) })
+		if !before.IsZero() {
+			kept := page[:0]
+			for _, c := range page {
+				if c.OpenTime.Before(before) {
+					kept = append(kept, c)
+				}
+			}
+			page = kept
+		}
+		// Only an empty page ends the history: Coinbase leaves out empty buckets, so short pages
+		// are normal, and the filter above makes the oldest time move back on every other page
+		if len(page) == 0 {
+			break
+		}
+
+		all = append(page, all...)
+		before = page[0].OpenTime
+		if len(all) < limit && !sleepContext(ctx, exchangePageDelay) {
+			return nil, ctx.Err()
+		}
+	}
+
+	if len(all) > limit {
+		all = all[len(all)-limit:]
+	}
+	return all, nil
+}
+
+// exchangeCandle builds a candle with the Binance-style close time (open + interval - 1ms)
+func exchangeCandle(openTime time.Time, interval BinanceInterval, open, high, low, close, volume float64) Candlestick {
+	return Candlestick{
+		OpenTime:  openTime,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		CloseTime: openTime.Add(IntervalDuration(interval) - time.Millisecond),
+	}
+}
+
+// CryptoExchangeNames lists the crypto providers of DefaultProviders for usage texts
+func CryptoExchangeNames() []string {
+	var names []string
+	for _, p := range DefaultProviders.Providers(MarketCrypto) {
+		names = append(names, strings.ToLower(p.Name()))
+	}
+	return names
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fixtureServer serves the testdata file named by route for each request
+// An empty name answers 404
+func fixtureServer(t *testing.T, route func(r *http.Request) string) *httptest.Server {
+	return fixtureStatusServer(t, http.StatusOK, route)
+}
+
+// fixtureStatusServer is fixtureServer answering with the given status code
+func fixtureStatusServer(t *testing.T, status int, route func(r *http.Request) string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := route(r)
+		if name == "" {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("fixture %s: %v", name, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// fixtureTime is 2024-05-01 plus the given number of hours, the start of the recorded 1h candles
+func fixtureTime(hours int) time.Time {
+	return time.Date(2024, 5, 1, hours, 0, 0, 0, time.UTC)
+}
+
+// checkCandleTimes fails unless the candles open at the given fixture hours, oldest first
+func checkCandleTimes(t *testing.T, candles []Candlestick, hours ...int) {
+	t.Helper()
+	if len(candles) != len(hours) {
+		t.Fatalf("got %d candles, want %d", len(candles), len(hours))
+	}
+	for i, h := range hours {
+		if !candles[i].OpenTime.Equal(fixtureTime(h)) {
+			t.Errorf("candle %d opens at %s, want %s", i, candles[i].OpenTime.UTC(), fixtureTime(h))
+		}
+		if want := fixtureTime(h).Add(time.Hour - time.Millisecond); !candles[i].CloseTime.Equal(want) {
+			t.Errorf("candle %d closes at %s, want %s", i, candles[i].CloseTime.UTC(), want)
+		}
+	}
+}
+
+func TestFetchCandlePagesKeepsPagingShortPages(t *testing.T) {
+	pages := [][]Candlestick{
+		{{OpenTime: fixtureTime(5)}, {OpenTime: fixtureTime(3)}}, // newest first, 4h bucket missing
+		{{OpenTime: fixtureTime(1)}, {OpenTime: fixtureTime(5)}}, // overlap is dropped
+		{{OpenTime: fixtureTime(0)}},
+		{},
+	}
+	calls := 0
+	candles, err := fetchCandlePages(context.Background(), 10, 3, func(before time.Time, n int) ([]Candlestick, error) {
+		page := pages[calls]
+		calls++
+		return page, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 4 {
+		t.Errorf("fetched %d pages, want 4 (stop on the empty page)", calls)
+	}
+	want := []int{0, 1, 3, 5}
+	if len(candles) != len(want) {
+		t.Fatalf("got %d candles, want %d", len(candles), len(want))
+	}
+	for i, h := range want {
+		if !candles[i].OpenTime.Equal(fixtureTime(h)) {
+			t.Errorf("candle %d opens at %s, want %s", i, candles[i].OpenTime, fixtureTime(h))
+		}
+	}
+}
+
+func TestFetchCandlePagesTrimsToLimit(t *testing.T) {
+	candles, err := fetchCandlePages(context.Background(), 2, 5, func(before time.Time, n int) ([]Candlestick, error) {
+		if n != 2 {
+			t.Errorf("requested %d candles, want 2", n)
+		}
+		return []Candlestick{{OpenTime: fixtureTime(2)}, {OpenTime: fixtureTime(1)}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candles) != 2 || !candles[0].OpenTime.Equal(fixtureTime(1)) || !candles[1].OpenTime.Equal(fixtureTime(2)) {
+		t.Errorf("got %v, want the 1h and 2h candles oldest first", candles)
+	}
+}
+
+func TestBybitProvider(t *testing.T) {
+	srv := fixtureServer(t, func(r *http.Request) string {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/v5/market/kline":
+			if q.Get("category") != "spot" || q.Get("symbol") != "BTCUSDT" || q.Get("interval") != "60" {
+				t.Errorf("unexpected kline query %s", r.URL.RawQuery)
+			}
+			switch q.Get("end") {
+			case "":
+				return "bybit_kline.json"
+			case "1714528799999": // 02:00 - 1ms
+				return "bybit_kline_older.json"
+			default:
+				return "bybit_kline_empty.json"
+			}
+		case "/v5/market/tickers":
+			if q.Get("symbol") == "BTCUSDT" {
+				return "bybit_tickers.json"
+			}
+			return "bybit_invalid_symbol.json"
+		}
+		return ""
+	})
+	p := BybitProvider{BaseURL: srv.URL}
+	ctx := context.Background()
+
+	candles, err := p.FetchCandles(ctx, "BTCUSDT", Interval1h, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCandleTimes(t, candles, 0, 1, 2, 3)
+	last := candles[3]
+	if last.Open != 60250.5 || last.High != 60410 || last.Low != 60100.1 || last.Close != 60388.2 || last.Volume != 152.431 || last.QuoteVolume != 9196214.53 {
+		t.Errorf("unexpected last candle %+v", last)
+	}
+
+	if price, err := p.GetCurrentPrice(ctx, "BTCUSDT"); err != nil || price != 60388.2 {
+		t.Errorf("GetCurrentPrice = %v, %v; want 60388.2", price, err)
+	}
+	if _, err := p.GetCurrentPrice(ctx, "NOPEUSDT"); !errors.Is(err, errSymbolNotListed) {
+		t.Errorf("GetCurrentPrice of an unknown symbol = %v, want errSymbolNotListed", err)
+	}
+	if listed, err := p.ValidateSymbol(ctx, "NOPEUSDT"); listed || err != nil {
+		t.Errorf("ValidateSymbol of an unknown symbol = %v, %v; want false, nil", listed, err)
+	}
+}
+
+func TestOKXProvider(t *testing.T) {
+	srv := fixtureServer(t, func(r *http.Request) string {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v5/market/candles":
+			if q.Get("instId") != "ETH-USDT" || q.Get("bar") != "1H" {
+				t.Errorf("unexpected candles query %s", r.URL.RawQuery)
+			}
+			switch q.Get("after") {
+			case "":
+				return "okx_candles.json"
+			case "1714528800000": // exclusive 02:00
+				return "okx_candles_older.json"
+			default:
+				return "okx_candles_empty.json"
+			}
+		case "/api/v5/market/ticker":
+			if q.Get("instId") == "ETH-USDT" {
+				return "okx_ticker.json"
+			}
+			return "okx_instrument_missing.json"
+		}
+		return ""
+	})
+	p := OKXProvider{BaseURL: srv.URL}
+	ctx := context.Background()
+
+	candles, err := p.FetchCandles(ctx, "ETH-USDT", Interval1h, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCandleTimes(t, candles, 1, 2, 3)
+	first := candles[0]
+	if first.Open != 3024.3 || first.High != 3025 || first.Low != 2990.06 || first.Close != 2998.7 || first.Volume != 1530.02 || first.QuoteVolume != 4599014.3 {
+		t.Errorf("unexpected first candle %+v", first)
+	}
+
+	if price, err := p.GetCurrentPrice(ctx, "ETH-USDT"); err != nil || price != 3018.02 {
+		t.Errorf("GetCurrentPrice = %v, %v; want 3018.02", price, err)
+	}
+	if listed, err := p.ValidateSymbol(ctx, "NOPE-USDT"); listed || err != nil {
+		t.Errorf("ValidateSymbol of an unknown instrument = %v, %v; want false, nil", listed, err)
+	}
+}
+
+func TestCoinbaseProvider(t *testing.T) {
+	srv := fixtureServer(t, func(r *http.Request) string {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("request without User-Agent")
+		}
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/products/BTC-USD/candles":
+			if q.Get("granularity") != "3600" {
+				t.Errorf("unexpected candles query %s", r.URL.RawQuery)
+			}
+			switch q.Get("end") {
+			case "":
+				return "coinbase_candles.json" // the 2h bucket had no trades
+			case "2024-05-01T00:59:59Z": // 8 candles left to fetch before 01:00
+				if q.Get("start") != "2024-04-30T16:59:59Z" {
+					t.Errorf("unexpected window start %s", q.Get("start"))
+				}
+				return "coinbase_candles_older.json"
+			default:
+				return "coinbase_candles_empty.json"
+			}
+		case "/products/BTC-USD/ticker":
+			return "coinbase_ticker.json"
+		}
+		return ""
+	})
+	p := CoinbaseProvider{BaseURL: srv.URL}
+	ctx := context.Background()
+
+	candles, err := p.FetchCandles(ctx, "BTC-USD", Interval1h, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCandleTimes(t, candles, 0, 1, 3)
+	last := candles[2]
+	if last.Open != 60240.12 || last.High != 60405.5 || last.Low != 60090.01 || last.Close != 60380 || last.Volume != 41.0821 {
+		t.Errorf("unexpected last candle %+v", last)
+	}
+
+	if price, err := p.GetCurrentPrice(ctx, "BTC-USD"); err != nil || price != 60380 {
+		t.Errorf("GetCurrentPrice = %v, %v; want 60380", price, err)
+	}
+	if listed, err := p.ValidateSymbol(ctx, "NOPE-USD"); listed || err != nil {
+		t.Errorf("ValidateSymbol of an unknown product = %v, %v; want false, nil", listed, err)
+	}
+}
+
+func TestKrakenProvider(t *testing.T) {
+	srv := fixtureServer(t, func(r *http.Request) string {
+		q := r.URL.Query()
+		if q.Get("pair") != "XBTUSD" {
+			return "kraken_unknown_pair.json"
+		}
+		switch r.URL.Path {
+		case "/0/public/OHLC":
+			if q.Get("interval") != "60" {
+				t.Errorf("unexpected OHLC query %s", r.URL.RawQuery)
+			}
+			return "kraken_ohlc.json"
+		case "/0/public/Ticker":
+			return "kraken_ticker.json"
+		}
+		return ""
+	})
+	p := KrakenProvider{BaseURL: srv.URL}
+	ctx := context.Background()
+
+	candles, err := p.FetchCandles(ctx, "XBTUSD", Interval1h, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCandleTimes(t, candles, 1, 2, 3)
+	last := candles[2]
+	if last.Open != 60240.1 || last.High != 60405.5 || last.Low != 60090 || last.Close != 60380 || last.Volume != 41.0821 {
+		t.Errorf("unexpected last candle %+v", last)
+	}
+	vwap, volume := 60262.8, 41.0821
+	if want := vwap * volume; last.QuoteVolume != want {
+		t.Errorf("QuoteVolume = %v, want vwap x volume %v", last.QuoteVolume, want)
+	}
+	if last.Trades != 0 {
+		t.Errorf("Trades = %d, want 0 without taker volume", last.Trades)
+	}
+
+	if price, err := p.GetCurrentPrice(ctx, "XBTUSD"); err != nil || price != 60380 {
+		t.Errorf("GetCurrentPrice = %v, %v; want 60380", price, err)
+	}
+	if listed, err := p.ValidateSymbol(ctx, "NOPEUSD"); listed || err != nil {
+		t.Errorf("ValidateSymbol of an unknown pair = %v, %v; want false, nil", listed, err)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+)
+
+// Fibonacci retracement and extension of the most recent significant swing leg
+
+// fibMinLegATR is the smallest leg, in ATRs, worth measuring
+const fibMinLegATR = 3.0
+
+// Fibonacci ratios of the leg
+var (
+	fibRetracements = []float64{0.236, 0.382, 0.5, 0.618, 0.786}
+	fibExtensions   = []float64{1.272, 1.618, 2.0, 2.618}
+)
+
+// colorFib is the color of the fibonacci levels on charts
+var colorFib = color.NRGBA{R: 186, G: 104, B: 200, A: 255}
+
+// FibLevel is the price at a fibonacci ratio of a leg
+type FibLevel struct {
+	Ratio float64
+	Price float64
+}
+
+// FibonacciLevels are the retracement and extension levels of a swing leg
+// Retracements are measured back from End toward Start; extensions project the leg from Start beyond End
+type FibonacciLevels struct {
+	Direction    string     // BULLISH (leg up from a swing low) or BEARISH (leg down from a swing high)
+	Start        SwingPoint // beginning of the leg (100% retracement)
+	End          SwingPoint // extreme of the leg (0% retracement), may be beyond the last confirmed swing
+	Retracements []FibLevel
+	Extensions   []FibLevel
+}
+
+// DetectFibonacci measures the most recent leg between opposite swings that spans at least
+// fibMinLegATR ATRs; the leg starts at the extreme swing of the run before its end swing and ends
+// at the most extreme price reached since that swing. Returns nil when no leg qualifies
+func DetectFibonacci(candles []Candlestick, swings []SwingPoint, atr float64) *FibonacciLevels {
+	if len(candles) == 0 || atr <= 0 {
+		return nil
+	}
+
+	for k := len(swings) - 1; k > 0; k-- {
+		end := swings[k]
+
+		// Extreme swing of the opposite kind in the run just before end
+		start, found := SwingPoint{}, false
+		for j := k - 1; j >= 0; j-- {
+			s := swings[j]
+			if s.High == end.High {
+				if found {
+					break
+				}
+				continue
+			}
+			if !found || (end.High && s.Price < start.Price) || (!end.High && s.Price > start.Price) {
+				start, found = s, true
+			}
+		}
+		if !found {
+			continue
+		}
+
+		// The leg may have run further since the end swing was confirmed
+		for i := end.Index + 1; i < len(candles); i++ {
+			c := candles[i]
+			if end.High && c.High > end.Price {
+				end = SwingPoint{Index: i, Time: c.OpenTime, Price: c.High, High: true}
+			} else if !end.High && c.Low < end.Price {
+				end = SwingPoint{Index: i, Time: c.OpenTime, Price: c.Low}
+			}
+		}
+
+		if math.Abs(end.Price-start.Price) >= atr*fibMinLegATR {
+			return newFibonacciLevels(start, end)
+		}
+	}
+	return nil
+}
+
+// newFibonacciLevels computes the levels of the leg from start to end
+func newFibonacciLevels(start, end SwingPoint) *FibonacciLevels {
+	f := &FibonacciLevels{Direction: SMCBearish, Start: start, End: end}
+	if end.High {
+		f.Direction = SMCBullish
+	}
+	move := end.Price - start.Price
+	for _, r := range fibRetracements {
+		f.Retracements = append(f.Retracements, FibLevel{Ratio: r, Price: end.Price - move*r})
+	}
+	for _, r := range fibExtensions {
+		f.Extensions = append(f.Extensions, FibLevel{Ratio: r, Price: start.Price + move*r})
+	}
+	return f
+}
+
+// Retracement returns how far price has retraced the leg (0 at End, 1 at Start)
+func (f *FibonacciLevels) Retracement(price float64) float64 {
+	return (f.End.Price - price) / (f.End.Price - f.Start.Price)
+}
+
+// FormatFibonacciForAI formats the leg and its levels as lines of a timeframe section
+func FormatFibonacciForAI(f *FibonacciLevels, close float64, prec InstrumentPrecision) string {
+	p := prec.Format
+	when := func(t time.Time) string { return t.UTC().Format("01-02 15:04") }
+	levels := func(levels []FibLevel) string {
+		parts := make([]string, len(levels))
+		for i, l := range levels {
+			parts[i] = fmt.Sprintf("%.3f %s", l.Ratio, p(l.Price))
+		}
+		return strings.Join(parts, " | ")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Fib Leg: %s %s @%s -> %s @%s | Price at %.1f%% retracement\n",
+		f.Direction, p(f.Start.Price), when(f.Start.Time), p(f.End.Price), when(f.End.Time), f.Retracement(close)*100))
+	sb.WriteString(fmt.Sprintf("Fib Retracement: %s\n", levels(f.Retracements)))
+	sb.WriteString(fmt.Sprintf("Fib Extension: %s\n", levels(f.Extensions)))
+	return sb.String()
+}
+
+// FibonacciAnnotations draws the retracement and extension levels from the start of the leg
+func FibonacciAnnotations(f *FibonacciLevels) []ChartAnnotation {
+	if f == nil {
+		return nil
+	}
+	var out []ChartAnnotation
+	for _, l := range append(append([]FibLevel{}, f.Retracements...), f.Extensions...) {
+		out = append(out, ChartAnnotation{Kind: AnnotationLevel, Label: fmt.Sprintf("Fib %.3f", l.Ratio), Source: SourceAlgo,
+			From: f.Start.Time, Price: l.Price, Color: colorFib, Dashed: true})
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -100,39 +101,24 @@ func NormalizeForexSymbol(input string) (string, string, error) {
 func GetForexTimeframesForMode(mode TradingMode) []YahooInterval {
 	switch mode {
 	case TradingModeScalping:
-		// Scalping: fokus timeframe kecil (5m, 15m, 1H, 1D)
-		// Note: 4H not available on Yahoo, using 1H instead
-		return []YahooInterval{YahooInterval5m, YahooInterval15m, YahooInterval1h, YahooInterval1d}
+		// Scalping: fokus timeframe kecil (5m, 15m, 1H, 4H, 1D)
+		// Note: 4H is resampled from Yahoo 1H data
+		return []YahooInterval{YahooInterval5m, YahooInterval15m, YahooInterval1h, YahooInterval4h, YahooInterval1d}
 	case TradingModeSwing:
-		// Swing: full top-down (15m, 1H, 1D, 1W)
-		return []YahooInterval{YahooInterval15m, YahooInterval1h, YahooInterval1d, YahooInterval1wk}
+		// Swing: full top-down (15m, 1H, 4H, 1D, 1W)
+		return []YahooInterval{YahooInterval15m, YahooInterval1h, YahooInterval4h, YahooInterval1d, YahooInterval1wk}
 	case TradingModeIntraday:
-		// Intraday: shorter timeframes (5m, 15m, 1H, 1D)
-		return []YahooInterval{YahooInterval5m, YahooInterval15m, YahooInterval1h, YahooInterval1d}
+		// Intraday: shorter timeframes (5m, 15m, 1H, 4H, 1D)
+		return []YahooInterval{YahooInterval5m, YahooInterval15m, YahooInterval1h, YahooInterval4h, YahooInterval1d}
 	default:
-		// Default: balanced (15m, 1H, 1D, 1W)
-		return []YahooInterval{YahooInterval15m, YahooInterval1h, YahooInterval1d, YahooInterval1wk}
+		// Default: balanced (15m, 1H, 4H, 1D, 1W)
+		return []YahooInterval{YahooInterval15m, YahooInterval1h, YahooInterval4h, YahooInterval1d, YahooInterval1wk}
 	}
 }
 
 // FetchForexMultiTimeframeData fetches forex data for all timeframes
-func FetchForexMultiTimeframeData(symbol string, mode TradingMode, candleLimit int) ([]CandleDataSummary, error) {
-	timeframes := GetForexTimeframesForMode(mode)
-	summaries := make([]CandleDataSummary, 0, len(timeframes))
-
-	for _, tf := range timeframes {
-		candles, err := FetchYahooCandlesticks(symbol, tf, candleLimit)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch %s: %w", tf, err)
-		}
-
-		// Convert Yahoo interval to Binance interval for compatibility with existing analysis
-		binanceInterval := ConvertYahooToBinanceInterval(tf)
-		summary := AnalyzeCandlestickData(candles, binanceInterval)
-		summaries = append(summaries, summary)
-	}
-
-	return summaries, nil
+func FetchForexMultiTimeframeData(ctx context.Context, symbol string, mode TradingMode, candleLimit int) (MultiTimeframeResult, error) {
+	return FetchProviderMultiTimeframeData(ctx, NewCachedProvider(YahooProvider{}, DefaultCandleCache), symbol, GetTimeframesForMarket(MarketForex, mode), candleLimit)
 }
 
 // ConvertYahooToBinanceInterval converts Yahoo interval to Binance interval for display
@@ -160,7 +146,7 @@ func ConvertYahooToBinanceInterval(yi YahooInterval) BinanceInterval {
 }
 
 // FormatForexDataForAI formats forex multi-timeframe data for AI analysis
-func FormatForexDataForAI(symbol, displayName string, summaries []CandleDataSummary, mode TradingMode) string {
+func FormatForexDataForAI(symbol, displayName string, prec InstrumentPrecision, summaries []CandleDataSummary, mode TradingMode) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("=== FOREX MULTI-TIMEFRAME DATA ANALYSIS ===\n"))
@@ -170,29 +156,16 @@ func FormatForexDataForAI(symbol, displayName string, summaries []CandleDataSumm
 	sb.WriteString(fmt.Sprintf("Data Source: Yahoo Finance\n\n"))
 
 	for _, s := range summaries {
-		sb.WriteString(fmt.Sprintf("--- %s TIMEFRAME ---\n", GetTimeframeName(s.Interval)))
-		sb.WriteString(fmt.Sprintf("Period: %s to %s\n", s.StartTime.Format("2006-01-02 15:04"), s.EndTime.Format("2006-01-02 15:04")))
-		sb.WriteString(fmt.Sprintf("Candles Analyzed: %d\n", s.CandleCount))
-		sb.WriteString(fmt.Sprintf("Open: %.5f | High: %.5f | Low: %.5f | Close: %.5f\n", s.Open, s.High, s.Low, s.Close))
-		sb.WriteString(fmt.Sprintf("Price Change: %.2f%%\n", s.PriceChange))
-		sb.WriteString(fmt.Sprintf("MA20: %.5f | MA50: %.5f\n", s.MA20, s.MA50))
-		sb.WriteString(fmt.Sprintf("RSI(14): %.1f\n", s.RSI))
-		sb.WriteString(fmt.Sprintf("Trend: %s | Volatility: %s\n", s.Trend, s.Volatility))
-
-		// Last candles
-		sb.WriteString("Last 10 Candles (Time|O|H|L|C|Change|Type):\n")
-		for _, c := range s.LastCandles {
-			sb.WriteString(fmt.Sprintf("  %s | %.5f | %.5f | %.5f | %.5f | %+.2f%% | %s\n",
-				c.Time, c.O, c.H, c.L, c.C, c.Change, c.Type))
-		}
-		sb.WriteString("\n")
+		writeTimeframeSection(&sb, s, prec)
 	}
 
+	sb.WriteString(FormatSupportResistanceForAI(DetectSupportResistance(summaries), prec))
+
 	return sb.String()
 }
 
 // GenerateForexAnalysisPrompt creates a specialized prompt for forex analysis
-func GenerateForexAnalysisPrompt(mode TradingMode, symbol, displayName, dataContext string) string {
+func GenerateForexAnalysisPrompt(mode TradingMode, symbol, displayName string, prec InstrumentPrecision, dataContext string) string {
 	baseRole := ""
 	strategy := ""
 
@@ -247,18 +220,20 @@ LANGKAH 1: EXTERNAL DATA VALIDATION
 
 LANGKAH 2: MULTI-TIMEFRAME ANALYSIS
 - Analisa dari timeframe TERBESAR ke TERKECIL
+%s
 - Identifikasi: Trend utama di HTF (Daily/Weekly)
 - Cari entry presisi di LTF (1H/15m)
 - Pastikan confluence antara HTF dan LTF
 
 LANGKAH 3: SMART MONEY ANALYSIS
+%s
 - Order Blocks (OB) di level psikologis (00, 50, 20, 80)
 - Fair Value Gaps (FVG) / Imbalance
 - Break of Structure (BOS) / Change of Character (ChoCh)
 - Liquidity zones (Equal highs/lows)
 
 LANGKAH 4: ENTRY SETUP
-- Entry Point yang optimal (harga spesifik dengan 5 desimal untuk forex)
+- Entry Point yang optimal (harga spesifik dengan %s)
 - Stoploss (behind structure / invalidation level)
 - Take Profit 1, 2, 3 (berdasarkan structure targets)
 - Risk:Reward Ratio
@@ -268,7 +243,7 @@ CRITICAL RULE:
 1. GUNAKAN FORMAT HTML (Telegram Compatible).
 2. Escape karakter < > & di dalam teks biasa.
 3. GUNAKAN Code Block "diff" untuk warna merah/hijau.
-4. BERIKAN HARGA SPESIFIK untuk Entry, SL, TP (dengan 5 desimal untuk forex).
+4. BERIKAN HARGA SPESIFIK untuk Entry, SL, TP (dengan %s).
 5. PERHATIKAN PIP VALUE dan SPREAD dalam analisa.
 --------------------------------------------------------
 
@@ -286,6 +261,8 @@ HTF Trend (Daily/Weekly): <b>[BULLISH/BEARISH]</b>
 LTF Trend (1H/15m): <b>[BULLISH/BEARISH]</b>
 Key Support: [level harga]
 Key Resistance: [level harga]
+Demand Zone: [harga bawah] - [harga atas]
+Supply Zone: [harga bawah] - [harga atas]
 Volatility: [Low/Med/High]
 Active Session: [Asia/London/NY]
 
@@ -317,5 +294,5 @@ Active Session: [Asia/London/NY]
 
 ---
 <i>Generated by Antigravity AI • FOREX Analysis • Yahoo Finance Data</i>
-`, baseRole, dataContext, strategy, displayName, symbol, displayName, displayName, getTradingModeName(mode))
+`, baseRole, dataContext, strategy, displayName, symbol, displayName, promptRegimeRule, promptDataRules, prec.Describe(), prec.Describe(), displayName, getTradingModeName(mode))
 }
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Binance USDⓈ-M futures endpoints
+const (
+	BinanceFuturesBaseURL   = "https://fapi.binance.com"
+	BinanceFuturesStreamURL = "wss://fstream.binance.com/ws/!forceOrder@arr"
+)
+
+// Futures summary settings
+const (
+	futuresStatsPeriod    = "1h" // period for open interest / long-short history
+	futuresStatsLimit     = 24   // 24 x 1h = 24h change
+	futuresFundingLimit   = 21   // 21 x 8h = 7 days of funding
+	liquidationWindow     = 24 * time.Hour
+	maxLiquidationsPerSym = 2000
+)
+
+// FundingRatePoint is one settled funding rate
+type FundingRatePoint struct {
+	Time time.Time
+	Rate float64 // fraction, e.g. 0.0001 = 0.01%
+}
+
+// LiquidationSnapshot aggregates forced orders for a symbol over a window
+type LiquidationSnapshot struct {
+	Window       time.Duration
+	Count        int
+	LongLiqUSD   float64 // SELL forced orders close longs
+	ShortLiqUSD  float64 // BUY forced orders close shorts
+	LargestUSD   float64
+	LargestSide  string // LONG or SHORT
+	LastEventAgo time.Duration
+}
+
+// FuturesDataSummary is a summarized view of perpetual futures positioning
+type FuturesDataSummary struct {
+	Symbol             string
+	MarkPrice          float64
+	IndexPrice         float64
+	Basis              float64 // (mark - index) / index, percent
+	FundingRate        float64 // current/predicted funding, fraction
+	NextFundingTime    time.Time
+	AvgFundingRate     float64 // average of FundingHistory, fraction
+	FundingHistory     []FundingRatePoint
+	OpenInterest       float64 // contracts (base asset)
+	OpenInterestValue  float64 // USD
+	OIChange24h        float64 // percent
+	TopTraderLongShort float64 // top trader position long/short ratio
+	TopTraderLongPct   float64
+	GlobalLongShort    float64 // all accounts long/short ratio
+	PerpSpotBasis      float64 // (perp close - spot close) / spot close of the last 1h candle, percent
+	AvgPerpSpotBasis   float64 // average PerpSpotBasis over the matched 1h candles, percent
+	PerpSpotVolume     float64 // perp / spot volume over the matched 1h candles
+	PerpSpotCandles    int     // 1h candles matched between perp and spot klines (0 = unavailable)
+	Liquidations       *LiquidationSnapshot
+	Positioning        string   // CROWDED LONG, CROWDED SHORT, BALANCED
+	Unavailable        []string // sections that failed to load
+}
+
+// fetchFuturesJSON GETs a futures endpoint and decodes the JSON response into v
+func fetchFuturesJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	endpoint := fmt.Sprintf("%s%s?%s", BinanceFuturesBaseURL, path, query.Encode())
+	body, _, err := DefaultHTTPClient.Get(ctx, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse JSON from %s: %w", path, err)
+	}
+	return nil
+}
+
+// FetchFuturesCandlesticks fetches perpetual futures klines (same format as spot)
+func FetchFuturesCandlesticks(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	if limit > 1500 {
+		limit = 1500
+	}
+	if limit < 1 {
+		limit = 200
+	}
+
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("interval", string(interval))
+	query.Set("limit", strconv.Itoa(limit))
+
+	endpoint := fmt.Sprintf("%s/fapi/v1/klines?%s", BinanceFuturesBaseURL, query.Encode())
+	body, _, err := DefaultHTTPClient.Get(ctx, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("binance futures API error: %w", err)
+	}
+
+	return parseBinanceKlines(body)
+}
+
+// FetchFuturesData fetches premium index, funding, open interest and long/short data
+// Only the premium index is required (it proves the symbol has a perpetual contract);
+// other sections are optional and listed in Unavailable when they fail
+func FetchFuturesData(ctx context.Context, symbol string) (FuturesDataSummary, error) {
+	summary := FuturesDataSummary{Symbol: symbol}
+	symbolQuery := url.Values{}
+	symbolQuery.Set("symbol", symbol)
+
+	// 1. Premium index: mark/index price and predicted funding
+	var premium struct {
+		MarkPrice       string `json:"markPrice"`
+		IndexPrice      string `json:"indexPrice"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := fetchFuturesJSON(ctx, "/fapi/v1/premiumIndex", symbolQuery, &premium); err != nil {
+		return summary, fmt.Errorf("no USDⓈ-M perpetual for %s: %w", symbol, err)
+	}
+	summary.MarkPrice, _ = strconv.ParseFloat(premium.MarkPrice, 64)
+	summary.IndexPrice, _ = strconv.ParseFloat(premium.IndexPrice, 64)
+	summary.FundingRate, _ = strconv.ParseFloat(premium.LastFundingRate, 64)
+	summary.NextFundingTime = time.UnixMilli(premium.NextFundingTime)
+	if summary.IndexPrice > 0 {
+		summary.Basis = (summary.MarkPrice - summary.IndexPrice) / summary.IndexPrice * 100
+	}
+
+	// 2. Funding history
+	fundingQuery := url.Values{}
+	fundingQuery.Set("symbol", symbol)
+	fundingQuery.Set("limit", strconv.Itoa(futuresFundingLimit))
+	var funding []struct {
+		FundingRate string `json:"fundingRate"`
+		FundingTime int64  `json:"fundingTime"`
+	}
+	if err := fetchFuturesJSON(ctx, "/fapi/v1/fundingRate", fundingQuery, &funding); err != nil {
+		summary.Unavailable = append(summary.Unavailable, "funding history")
+	} else if len(funding) > 0 {
+		total := 0.0
+		for _, f := range funding {
+			rate, _ := strconv.ParseFloat(f.FundingRate, 64)
+			summary.FundingHistory = append(summary.FundingHistory, FundingRatePoint{Time: time.UnixMilli(f.FundingTime), Rate: rate})
+			total += rate
+		}
+		summary.AvgFundingRate = total / float64(len(funding))
+	}
+
+	statsQuery := url.Values{}
+	statsQuery.Set("symbol", symbol)
+	statsQuery.Set("period", futuresStatsPeriod)
+	statsQuery.Set("limit", strconv.Itoa(futuresStatsLimit))
+
+	// 3. Open interest history
+	var oiHist []struct {
+		SumOpenInterest      string `json:"sumOpenInterest"`
+		SumOpenInterestValue string `json:"sumOpenInterestValue"`
+	}
+	if err := fetchFuturesJSON(ctx, "/futures/data/openInterestHist", statsQuery, &oiHist); err != nil || len(oiHist) == 0 {
+		summary.Unavailable = append(summary.Unavailable, "open interest")
+	} else {
+		first, _ := strconv.ParseFloat(oiHist[0].SumOpenInterest, 64)
+		last := oiHist[len(oiHist)-1]
+		summary.OpenInterest, _ = strconv.ParseFloat(last.SumOpenInterest, 64)
+		summary.OpenInterestValue, _ = strconv.ParseFloat(last.SumOpenInterestValue, 64)
+		if first > 0 {
+			summary.OIChange24h = (summary.OpenInterest - first) / first * 100
+		}
+	}
+
+	// 4. Top trader long/short position ratio
+	var topRatio []struct {
+		LongShortRatio string `json:"longShortRatio"`
+		LongAccount    string `json:"longAccount"`
+	}
+	if err := fetchFuturesJSON(ctx, "/futures/data/topLongShortPositionRatio", statsQuery, &topRatio); err != nil || len(topRatio) == 0 {
+		summary.Unavailable = append(summary.Unavailable, "top trader long/short")
+	} else {
+		last := topRatio[len(topRatio)-1]
+		summary.TopTraderLongShort, _ = strconv.ParseFloat(last.LongShortRatio, 64)
+		longFraction, _ := strconv.ParseFloat(last.LongAccount, 64)
+		summary.TopTraderLongPct = longFraction * 100
+	}
+
+	// 5. Global account long/short ratio
+	var globalRatio []struct {
+		LongShortRatio string `json:"longShortRatio"`
+	}
+	if err := fetchFuturesJSON(ctx, "/futures/data/globalLongShortAccountRatio", statsQuery, &globalRatio); err != nil || len(globalRatio) == 0 {
+		summary.Unavailable = append(summary.Unavailable, "global long/short")
+	} else {
+		summary.GlobalLongShort, _ = strconv.ParseFloat(globalRatio[len(globalRatio)-1].LongShortRatio, 64)
+	}
+
+	// 6. Perp vs spot basis and volume from the 1h klines of both markets
+	perp, perpErr := FetchFuturesCandlesticks(ctx, symbol, Interval1h, futuresStatsLimit)
+	spot, spotErr := FetchCandlesticks(ctx, symbol, Interval1h, futuresStatsLimit)
+	if perpErr != nil || spotErr != nil || !applyPerpSpotBasis(&summary, perp, spot) {
+		summary.Unavailable = append(summary.Unavailable, "perp/spot basis")
+	}
+
+	// 7. Liquidations (only available while the tracker stream is running)
+	if snapshot, ok := DefaultLiquidationTracker.Snapshot(symbol, liquidationWindow); ok {
+		summary.Liquidations = &snapshot
+	}
+
+	summary.Positioning = classifyFuturesPositioning(summary)
+	return summary, nil
+}
+
+// applyPerpSpotBasis compares perp and spot candles opening at the same time
+// Returns false when no candles match
+func applyPerpSpotBasis(s *FuturesDataSummary, perp, spot []Candlestick) bool {
+	spotByTime := make(map[int64]Candlestick, len(spot))
+	for _, c := range spot {
+		spotByTime[c.OpenTime.UnixMilli()] = c
+	}
+
+	var basisTotal, perpVolume, spotVolume float64
+	matched := 0
+	for _, p := range perp {
+		sc, ok := spotByTime[p.OpenTime.UnixMilli()]
+		if !ok || sc.Close <= 0 {
+			continue
+		}
+		s.PerpSpotBasis = (p.Close - sc.Close) / sc.Close * 100
+		basisTotal += s.PerpSpotBasis
+		perpVolume += p.Volume
+		spotVolume += sc.Volume
+		matched++
+	}
+	if matched == 0 {
+		return false
+	}
+	s.PerpSpotCandles = matched
+	s.AvgPerpSpotBasis = basisTotal / float64(matched)
+	if spotVolume > 0 {
+		s.PerpSpotVolume = perpVolume / spotVolume
+	}
+	return true
+}
+
+// classifyFuturesPositioning labels crowding from funding and top trader ratio
+func classifyFuturesPositioning(s FuturesDataSummary) string {
+	switch {
+	case s.FundingRate >= 0.0005 || (s.FundingRate > 0.0001 && s.TopTraderLongShort >= 2):
+		return "CROWDED LONG"
+	case s.FundingRate <= -0.0003 || (s.FundingRate < 0 && s.TopTraderLongShort > 0 && s.TopTraderLongShort <= 0.7):
+		return "CROWDED SHORT"
+	default:
+		return "BALANCED"
+	}
+}
+
+// FormatFuturesDataForAI formats the futures summary as a data context section
+func FormatFuturesDataForAI(s FuturesDataSummary, prec InstrumentPrecision) string {
+	var sb strings.Builder
+
+	sb.WriteString("--- FUTURES DATA (Binance USDⓈ-M Perpetual) ---\n")
+	sb.WriteString(fmt.Sprintf("Mark Price: %s | Index Price: %s | Basis: %+.3f%%\n", prec.Format(s.MarkPrice), prec.Format(s.IndexPrice), s.Basis))
+	sb.WriteString(fmt.Sprintf("Funding Rate: %+.4f%% (next: %s UTC)\n", s.FundingRate*100, s.NextFundingTime.UTC().Format("2006-01-02 15:04")))
+	if len(s.FundingHistory) > 0 {
+		sb.WriteString(fmt.Sprintf("Avg Funding (%d periods): %+.4f%%\n", len(s.FundingHistory), s.AvgFundingRate*100))
+	}
+	if s.OpenInterest > 0 {
+		sb.WriteString(fmt.Sprintf("Open Interest: %.2f (%.0f USD) | 24h Change: %+.2f%%\n", s.OpenInterest, s.OpenInterestValue, s.OIChange24h))
+	}
+	if s.TopTraderLongShort > 0 {
+		sb.WriteString(fmt.Sprintf("Top Trader Long/Short (positions): %.2f (%.1f%% long)\n", s.TopTraderLongShort, s.TopTraderLongPct))
+	}
+	if s.GlobalLongShort > 0 {
+		sb.WriteString(fmt.Sprintf("Global Long/Short (accounts): %.2f\n", s.GlobalLongShort))
+	}
+	if s.PerpSpotCandles > 0 {
+		sb.WriteString(fmt.Sprintf("Perp vs Spot (1h closes, %d candles): Basis %+.3f%% | Avg %+.3f%% | Perp/Spot Volume %.2fx\n",
+			s.PerpSpotCandles, s.PerpSpotBasis, s.AvgPerpSpotBasis, s.PerpSpotVolume))
+	}
+	if s.Liquidations != nil {
+		l := s.Liquidations
+		// The stream pushes at most one forced order per symbol per second, so totals are a floor
+		sb.WriteString(fmt.Sprintf("Liquidations (%s, LOWER BOUND - stream sampled max 1 order/sec): %d orders | Longs: >=%.0f USD | Shorts: >=%.0f USD | Largest: %.0f USD (%s)\n",
+			l.Window, l.Count, l.LongLiqUSD, l.ShortLiqUSD, l.LargestUSD, l.LargestSide))
+	} else {
+		sb.WriteString("Liquidations: unavailable\n")
+	}
+	sb.WriteString(fmt.Sprintf("Positioning: %s\n", s.Positioning))
+	if len(s.Unavailable) > 0 {
+		sb.WriteString(fmt.Sprintf("Unavailable: %s\n", strings.Join(s.Unavailable, ", ")))
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// liquidationEvent is one forced order
+type liquidationEvent struct {
+	Time     time.Time
+	Side     string // LONG or SHORT (position that was liquidated)
+	ValueUSD float64
+}
+
+// LiquidationTracker collects forced orders from the !forceOrder@arr stream
+// Binance has no public REST endpoint for liquidation history, so snapshots
+// only cover the time the tracker has been running. The stream pushes at most the
+// latest forced order per symbol each second, so counts and totals are a lower bound
+type LiquidationTracker struct {
+	mu        sync.RWMutex
+	events    map[string][]liquidationEvent // key: symbol
+	startedAt time.Time
+	aliveAt   time.Time // last message, ping or pong on the stream
+}
+
+// NewLiquidationTracker creates an idle tracker
+func NewLiquidationTracker() *LiquidationTracker {
+	return &LiquidationTracker{events: make(map[string][]liquidationEvent)}
+}
+
+// DefaultLiquidationTracker is the shared tracker started by the bot
+var DefaultLiquidationTracker = NewLiquidationTracker()
+
+// Run streams liquidations until ctx is cancelled, reconnecting with exponential backoff
+func (t *LiquidationTracker) Run(ctx context.Context) {
+	backoff := streamMinBackoff
+
+	for ctx.Err() == nil {
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, BinanceFuturesStreamURL, nil)
+		if err != nil {
+			log.Printf("⚠️ [LIQ] Connect failed: %v (retry in %s)", err, backoff)
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+			backoff *= 2
+			if backoff > streamMaxBackoff {
+				backoff = streamMaxBackoff
+			}
+			continue
+		}
+
+		backoff = streamMinBackoff
+		t.mu.Lock()
+		if t.startedAt.IsZero() {
+			t.startedAt = time.Now()
+		}
+		t.mu.Unlock()
+		t.markAlive()
+		log.Printf("✅ [LIQ] Connected to liquidation stream")
+
+		stop := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+			case <-stop:
+			}
+		}()
+		keepAlive(conn, stop, t.markAlive)
+
+		err = t.readLoop(conn)
+		close(stop)
+		conn.Close()
+
+		if ctx.Err() == nil {
+			log.Printf("⚠️ [LIQ] Disconnected: %v (reconnecting)", err)
+		}
+	}
+}
+
+// readLoop records forced orders until the connection fails
+func (t *LiquidationTracker) readLoop(conn *websocket.Conn) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		extendReadDeadline(conn)
+		t.markAlive()
+
+		var payload struct {
+			Order struct {
+				Symbol       string `json:"s"`
+				Side         string `json:"S"`
+				AveragePrice string `json:"ap"`
+				FilledQty    string `json:"z"`
+				TradeTime    int64  `json:"T"`
+			} `json:"o"`
+		}
+		if err := json.Unmarshal(message, &payload); err != nil {
+			continue
+		}
+
+		o := payload.Order
+		price, _ := strconv.ParseFloat(o.AveragePrice, 64)
+		qty, _ := strconv.ParseFloat(o.FilledQty, 64)
+		side := "LONG" // SELL forced order closes a long
+		if o.Side == "BUY" {
+			side = "SHORT"
+		}
+		t.record(o.Symbol, liquidationEvent{Time: time.UnixMilli(o.TradeTime), Side: side, ValueUSD: price * qty})
+	}
+}
+
+// markAlive records that the stream delivered a frame
+func (t *LiquidationTracker) markAlive() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.aliveAt = time.Now()
+}
+
+// record stores an event and drops events older than liquidationWindow
+func (t *LiquidationTracker) record(symbol string, ev liquidationEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := append(t.events[symbol], ev)
+	cutoff := time.Now().Add(-liquidationWindow)
+	start := 0
+	for start < len(events) && events[start].Time.Before(cutoff) {
+		start++
+	}
+	if len(events)-start > maxLiquidationsPerSym {
+		start = len(events) - maxLiquidationsPerSym
+	}
+	t.events[symbol] = append([]liquidationEvent(nil), events[start:]...)
+}
+
+// Snapshot aggregates liquidations of a symbol over the window
+// ok is false when the tracker has not been running, or its stream has been down or silent
+// for longer than the window (the totals would describe an older period)
+func (t *LiquidationTracker) Snapshot(symbol string, window time.Duration) (LiquidationSnapshot, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := time.Now()
+	if t.startedAt.IsZero() || now.Sub(t.aliveAt) > window {
+		return LiquidationSnapshot{}, false
+	}
+
+	// Report the window actually covered by the tracker
+	if covered := now.Sub(t.startedAt); covered < window {
+		window = covered.Round(time.Minute)
+	}
+
+	snapshot := LiquidationSnapshot{Window: window}
+	cutoff := now.Add(-window)
+	for _, ev := range t.events[symbol] {
+		if ev.Time.Before(cutoff) {
+			continue
+		}
+		snapshot.Count++
+		if ev.Side == "LONG" {
+			snapshot.LongLiqUSD += ev.ValueUSD
+		} else {
+			snapshot.ShortLiqUSD += ev.ValueUSD
+		}
+		if ev.ValueUSD > snapshot.LargestUSD {
+			snapshot.LargestUSD = ev.ValueUSD
+			snapshot.LargestSide = ev.Side
+		}
+		snapshot.LastEventAgo = now.Sub(ev.Time)
+	}
+
+	return snapshot, true
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default retry policy for data fetchers
+const (
+	httpDefaultTimeout = 15 * time.Second
+	httpMaxRetries     = 3
+	httpBaseBackoff    = 500 * time.Millisecond
+	httpMaxBackoff     = 10 * time.Second
+	httpMaxRetryAfter  = 60 * time.Second
+)
+
+// browserUserAgent avoids 403/429 responses from Yahoo Finance
+const browserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// HTTPStatusError is returned when the server answers with a non-2xx status
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d from %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// IsHTTPStatus reports whether err is an HTTPStatusError with the given status code
+func IsHTTPStatus(err error, statusCode int) bool {
+	var statusErr *HTTPStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == statusCode
+}
+
+// HTTPClient is a shared HTTP client with per-host timeouts, retries with
+// exponential backoff and jitter on 429/5xx, Retry-After support and
+// Binance request weight tracking
+type HTTPClient struct {
+	client       *http.Client
+	hostTimeouts map[string]time.Duration
+	maxRetries   int
+
+	weightMu sync.Mutex
+	weights  map[string]hostWeight // key: Binance API host
+}
+
+// hostWeight is the last X-MBX-USED-WEIGHT-1M value reported by a host
+type hostWeight struct {
+	used   int
+	minute time.Time // minute window the weight belongs to
+}
+
+// NewHTTPClient creates a client with the given per-host timeouts
+func NewHTTPClient(hostTimeouts map[string]time.Duration) *HTTPClient {
+	return &HTTPClient{
+		client:       &http.Client{},
+		hostTimeouts: hostTimeouts,
+		maxRetries:   httpMaxRetries,
+		weights:      make(map[string]hostWeight),
+	}
+}
+
+// DefaultHTTPClient is used by all market data fetchers
+var DefaultHTTPClient = NewHTTPClient(map[string]time.Duration{
+	"api.binance.com":           10 * time.Second,
+	"api.binance.us":            10 * time.Second,
+	"fapi.binance.com":          10 * time.Second,
+	"api.bybit.com":             10 * time.Second,
+	"www.okx.com":               10 * time.Second,
+	"api.exchange.coinbase.com": 10 * time.Second,
+	"api.kraken.com":            10 * time.Second,
+	"query1.finance.yahoo.com":  30 * time.Second,
+})
+
+// timeoutFor returns the per-attempt timeout for a host
+func (c *HTTPClient) timeoutFor(host string) time.Duration {
+	if t, ok := c.hostTimeouts[host]; ok {
+		return t
+	}
+	return httpDefaultTimeout
+}
+
+// Get performs a GET request with retries and returns the body and response headers
+// Non-2xx responses are returned as *HTTPStatusError
+func (c *HTTPClient) Get(ctx context.Context, rawURL string, header http.Header) ([]byte, http.Header, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+	// Each Binance host has its own weight budget (see binanceWeightLimits)
+	host := parsed.Host
+	_, isBinance := binanceWeightLimits[host]
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := retryDelay(attempt, lastErr)
+			if !sleepContext(ctx, wait) {
+				return nil, nil, ctx.Err()
+			}
+		}
+
+		if isBinance {
+			if err := c.waitForBinanceWeight(ctx, host); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		body, respHeader, err := c.doOnce(ctx, rawURL, parsed.Host, header)
+		if respHeader != nil && isBinance {
+			c.recordBinanceWeight(host, respHeader)
+		}
+		if err == nil {
+			return body, respHeader, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil || !isRetryable(err) {
+			return nil, respHeader, err
+		}
+		log.Printf("⚠️ [HTTP] %s failed (attempt %d/%d): %v", parsed.Host, attempt+1, c.maxRetries+1, err)
+	}
+
+	return nil, nil, lastErr
+}
+
+// retryableError carries the Retry-After hint of a retryable status response
+type retryableError struct {
+	*HTTPStatusError
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Unwrap() error { return e.HTTPStatusError }
+
+// doOnce performs a single attempt bounded by the host timeout
+func (c *HTTPClient) doOnce(ctx context.Context, rawURL, host string, header http.Header) ([]byte, http.Header, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeoutFor(host))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(attemptCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.Header, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		statusErr := &HTTPStatusError{URL: rawURL, StatusCode: resp.StatusCode, Body: string(body)}
+		// 429 rate limit, 418 Binance IP ban, 5xx server errors
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot || resp.StatusCode >= 500 {
+			return nil, resp.Header, &retryableError{
+				HTTPStatusError: statusErr,
+				retryAfter:      parseRetryAfter(resp.Header.Get("Retry-After")),
+			}
+		}
+		return nil, resp.Header, statusErr
+	}
+
+	return body, resp.Header, nil
+}
+
+// isRetryable reports whether a failed attempt should be retried
+func isRetryable(err error) bool {
+	var retryErr *retryableError
+	if errors.As(err, &retryErr) {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	// Network errors and timeouts are retryable, other statuses are not
+	return !errors.As(err, &statusErr)
+}
+
+// retryDelay returns the wait before the given attempt: Retry-After when the server
+// sent one, otherwise exponential backoff with full jitter
+func retryDelay(attempt int, lastErr error) time.Duration {
+	var retryErr *retryableError
+	if errors.As(lastErr, &retryErr) && retryErr.retryAfter > 0 {
+		return retryErr.retryAfter
+	}
+
+	backoff := httpBaseBackoff << (attempt - 1)
+	if backoff > httpMaxBackoff {
+		backoff = httpMaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+// parseRetryAfter parses a Retry-After header (seconds or HTTP date)
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = time.Until(at)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+	if wait > httpMaxRetryAfter {
+		return httpMaxRetryAfter
+	}
+	return wait
+}
+
+// recordBinanceWeight stores the X-MBX-USED-WEIGHT-1M value of a Binance host's response
+func (c *HTTPClient) recordBinanceWeight(host string, header http.Header) {
+	weight, err := strconv.Atoi(header.Get("X-MBX-USED-WEIGHT-1M"))
+	if err != nil {
+		return
+	}
+
+	c.weightMu.Lock()
+	defer c.weightMu.Unlock()
+	c.weights[host] = hostWeight{used: weight, minute: time.Now().Truncate(time.Minute)}
+}
+
+// BinanceUsedWeight returns the request weight a Binance host reported for the current minute
+func (c *HTTPClient) BinanceUsedWeight(host string) int {
+	c.weightMu.Lock()
+	defer c.weightMu.Unlock()
+	w := c.weights[host]
+	if !w.minute.Equal(time.Now().Truncate(time.Minute)) {
+		return 0 // weight resets every minute
+	}
+	return w.used
+}
+
+// waitForBinanceWeight blocks until the next minute window when the weight used
+// on host is close to its limit in binanceWeightLimits
+func (c *HTTPClient) waitForBinanceWeight(ctx context.Context, host string) error {
+	used, limit := c.BinanceUsedWeight(host), binanceWeightLimits[host]
+	if float64(used) < float64(limit)*binanceWeightSafetyRatio {
+		return nil
+	}
+
+	now := time.Now()
+	wait := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+	log.Printf("⏳ [BINANCE] %s used weight %d/%d, pausing %s", host, used, limit, wait.Round(time.Second))
+	if !sleepContext(ctx, wait) {
+		return ctx.Err()
+	}
+	return nil
+}
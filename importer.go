@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Candle import settings
+const (
+	maxImportCandles  = 5000
+	maxImportFileSize = 5 << 20 // 5 MB
+	maxImportDecimals = 10
+)
+
+// ImportFormat identifies the layout of an uploaded candle file
+type ImportFormat string
+
+const (
+	ImportFormatAuto       ImportFormat = ""
+	ImportFormatCSV        ImportFormat = "csv"
+	ImportFormatMetaTrader ImportFormat = "mt" // MT4 history center / MT5 export
+	ImportFormatJSON       ImportFormat = "json"
+)
+
+// importIntervals are the bar spacings an imported file may have
+var importIntervals = []BinanceInterval{Interval1m, Interval5m, Interval15m, Interval30m, Interval1h, Interval4h, Interval1d, Interval1w}
+
+// importTimeLayouts are tried in order for textual timestamps
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006.01.02 15:04:05",
+	"2006.01.02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"2006-01-02",
+	"2006.01.02",
+	"2006/01/02",
+	"02.01.2006",
+	"20060102 150405",
+	"20060102",
+}
+
+// CSVColumns maps OHLCV fields to zero-based column indexes (-1 = absent)
+type CSVColumns struct {
+	Date   int // date or full timestamp
+	Time   int // separate time of day column (MetaTrader)
+	Open   int
+	High   int
+	Low    int
+	Close  int
+	Volume int
+}
+
+// ImportOptions controls how a candle file is parsed
+type ImportOptions struct {
+	Format   ImportFormat
+	Columns  *CSVColumns    // nil = detect from the header or MetaTrader layout
+	Location *time.Location // timezone of timestamps without offset (nil = UTC)
+}
+
+// ImportResult is a parsed candle file
+type ImportResult struct {
+	Candles   []Candlestick
+	Interval  BinanceInterval // detected from the bar spacing
+	Format    ImportFormat
+	Skipped   int // rows that could not be parsed or had invalid OHLC
+	Precision InstrumentPrecision
+}
+
+// importRow is one parsed but unvalidated candle
+type importRow struct {
+	openTime               time.Time
+	open, high, low, close float64
+	volume                 float64
+}
+
+// ImportCandles reads OHLCV data from CSV, MetaTrader or JSON input
+func ImportCandles(r io.Reader, opts ImportOptions) (ImportResult, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImportFileSize+1))
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxImportFileSize {
+		return ImportResult{}, fmt.Errorf("file larger than %d MB", maxImportFileSize>>20)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+
+	format := opts.Format
+	if format == ImportFormatAuto {
+		trimmed := bytes.TrimSpace(data)
+		if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+			format = ImportFormatJSON
+		} else {
+			format = ImportFormatCSV
+		}
+	}
+
+	dec := &decimalTracker{}
+	var rows []importRow
+	var skipped int
+	if format == ImportFormatJSON {
+		rows, skipped, err = parseImportJSON(data, opts.Location, dec)
+	} else {
+		var detected ImportFormat
+		rows, skipped, detected, err = parseImportCSV(data, opts, dec)
+		format = detected
+	}
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	candles, invalid := buildImportCandles(rows)
+	skipped += invalid
+	if len(candles) < 2 {
+		return ImportResult{}, fmt.Errorf("need at least 2 valid candles, got %d (%d rows skipped)", len(candles), skipped)
+	}
+
+	interval, err := detectImportInterval(candles)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	dur := IntervalDuration(interval)
+	for i := range candles {
+		candles[i].CloseTime = candles[i].OpenTime.Add(dur - time.Millisecond)
+	}
+	if len(candles) > maxImportCandles {
+		candles = candles[len(candles)-maxImportCandles:]
+	}
+
+	return ImportResult{
+		Candles:   candles,
+		Interval:  interval,
+		Format:    format,
+		Skipped:   skipped,
+		Precision: dec.precision(),
+	}, nil
+}
+
+// parseImportCSV parses delimited text, detecting the delimiter, header and MetaTrader layout
+func parseImportCSV(data []byte, opts ImportOptions, dec *decimalTracker) ([]importRow, int, ImportFormat, error) {
+	delim := detectDelimiter(data)
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delim
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, 0, "", fmt.Errorf("empty file")
+	}
+
+	format := opts.Format
+	if format == ImportFormatAuto {
+		format = ImportFormatCSV
+	}
+	cols := opts.Columns
+	if header, ok := parseCSVHeader(records[0]); ok {
+		if cols == nil {
+			cols = &header
+		}
+		if strings.HasPrefix(strings.TrimSpace(records[0][0]), "<") {
+			format = ImportFormatMetaTrader // MT5 export: <DATE> <TIME> <OPEN> ...
+		}
+		records = records[1:]
+	} else if cols == nil {
+		// Headerless: MT4 history center (date,time,o,h,l,c,v) or time,o,h,l,c,v
+		first := records[0]
+		if len(first) >= 6 && strings.Contains(first[1], ":") && !strings.Contains(first[0], ":") {
+			cols = &CSVColumns{Date: 0, Time: 1, Open: 2, High: 3, Low: 4, Close: 5, Volume: 6}
+			format = ImportFormatMetaTrader
+		} else {
+			cols = &CSVColumns{Date: 0, Time: -1, Open: 1, High: 2, Low: 3, Close: 4, Volume: 5}
+		}
+	}
+	if cols.Date < 0 || cols.Open < 0 || cols.High < 0 || cols.Low < 0 || cols.Close < 0 {
+		return nil, 0, "", fmt.Errorf("CSV needs time, open, high, low and close columns")
+	}
+
+	// Semicolon files usually come from locales with a decimal comma
+	decimalComma := delim == ';'
+
+	var rows []importRow
+	skipped := 0
+	for _, rec := range records {
+		row, ok := parseCSVRecord(rec, *cols, opts.Location, decimalComma, dec)
+		if !ok {
+			skipped++
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, skipped, format, nil
+}
+
+// detectDelimiter picks tab, semicolon or comma from the first line
+func detectDelimiter(data []byte) rune {
+	line, _, _ := bufio.NewReader(bytes.NewReader(data)).ReadLine()
+	switch {
+	case bytes.Count(line, []byte("\t")) > 0:
+		return '\t'
+	case bytes.Count(line, []byte(";")) > 0:
+		return ';'
+	default:
+		return ','
+	}
+}
+
+// parseCSVHeader maps header names to columns; ok is false when the row is data
+func parseCSVHeader(record []string) (CSVColumns, bool) {
+	cols := CSVColumns{Date: -1, Time: -1, Open: -1, High: -1, Low: -1, Close: -1, Volume: -1}
+	found := false
+	for i, name := range record {
+		field := importFieldName(name)
+		if field == "" {
+			continue
+		}
+		found = true
+		set := func(idx *int) {
+			if *idx < 0 {
+				*idx = i
+			}
+		}
+		switch field {
+		case "date", "datetime":
+			set(&cols.Date)
+		case "time":
+			set(&cols.Time)
+		case "open":
+			set(&cols.Open)
+		case "high":
+			set(&cols.High)
+		case "low":
+			set(&cols.Low)
+		case "close":
+			set(&cols.Close)
+		case "volume":
+			set(&cols.Volume)
+		}
+	}
+	if !found {
+		return cols, false
+	}
+	// A lone "time" column holds the full timestamp
+	if cols.Date < 0 {
+		cols.Date, cols.Time = cols.Time, -1
+	}
+	return cols, true
+}
+
+// importFieldName returns the canonical OHLCV field for a header or JSON key ("" if unknown)
+func importFieldName(name string) string {
+	n := strings.ToLower(strings.TrimSpace(name))
+	n = strings.Trim(n, "<>\"")
+	n = strings.NewReplacer("_", "", " ", "").Replace(n)
+	switch n {
+	case "date", "day":
+		return "date"
+	case "datetime", "timestamp", "opentime", "ts", "t", "unix", "gmttime", "localtime":
+		return "datetime"
+	case "time", "hour":
+		return "time"
+	case "open", "o":
+		return "open"
+	case "high", "h":
+		return "high"
+	case "low", "l":
+		return "low"
+	case "close", "c", "last":
+		return "close"
+	case "volume", "vol", "v", "tickvol", "tickvolume", "realvolume":
+		return "volume"
+	default:
+		return ""
+	}
+}
+
+// parseCSVRecord parses one data row
+func parseCSVRecord(rec []string, cols CSVColumns, loc *time.Location, decimalComma bool, dec *decimalTracker) (importRow, bool) {
+	field := func(i int) string {
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+	price := func(i int) (float64, bool) {
+		s := field(i)
+		if decimalComma {
+			s = strings.ReplaceAll(s, ",", ".")
+		}
+		v, err := parsePriceNumber(s)
+		if err != nil {
+			return 0, false
+		}
+		dec.observe(s)
+		return v, true
+	}
+
+	stamp := field(cols.Date)
+	if t := field(cols.Time); t != "" {
+		stamp += " " + t
+	}
+	openTime, err := parseImportTime(stamp, loc)
+	if err != nil {
+		return importRow{}, false
+	}
+
+	var row importRow
+	var ok1, ok2, ok3, ok4 bool
+	row.openTime = openTime
+	row.open, ok1 = price(cols.Open)
+	row.high, ok2 = price(cols.High)
+	row.low, ok3 = price(cols.Low)
+	row.close, ok4 = price(cols.Close)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return importRow{}, false
+	}
+	if s := field(cols.Volume); s != "" {
+		if decimalComma {
+			s = strings.ReplaceAll(s, ",", ".")
+		}
+		row.volume, _ = parsePriceNumber(s)
+	}
+	return row, true
+}
+
+// parseImportJSON parses an array of [time, o, h, l, c, v] arrays or OHLCV objects
+// The array may be wrapped in an object under "candles", "data", "klines" or "values"
+func parseImportJSON(data []byte, loc *time.Location, dec *decimalTracker) ([]importRow, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	items, ok := root.([]any)
+	if obj, isObj := root.(map[string]any); isObj {
+		for _, key := range []string{"candles", "data", "klines", "values"} {
+			if items, ok = obj[key].([]any); ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, 0, fmt.Errorf("JSON must be an array of candles")
+	}
+
+	var rows []importRow
+	skipped := 0
+	for _, item := range items {
+		values := make(map[string]any)
+		switch v := item.(type) {
+		case []any:
+			// Binance kline layout: [openTime, open, high, low, close, volume, ...]
+			for i, name := range []string{"datetime", "open", "high", "low", "close", "volume"} {
+				if i < len(v) {
+					values[name] = v[i]
+				}
+			}
+		case map[string]any:
+			for key, val := range v {
+				if field := importFieldName(key); field != "" {
+					if field == "date" || field == "time" {
+						field = "datetime"
+					}
+					if _, seen := values[field]; !seen {
+						values[field] = val
+					}
+				}
+			}
+		}
+
+		row, ok := parseJSONRow(values, loc, dec)
+		if !ok {
+			skipped++
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, skipped, nil
+}
+
+// parseJSONRow converts canonical field values into a row
+func parseJSONRow(values map[string]any, loc *time.Location, dec *decimalTracker) (importRow, bool) {
+	text := func(name string) string {
+		switch v := values[name].(type) {
+		case json.Number:
+			return v.String()
+		case string:
+			return strings.TrimSpace(v)
+		default:
+			return ""
+		}
+	}
+	price := func(name string) (float64, bool) {
+		s := text(name)
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		dec.observe(s)
+		return v, true
+	}
+
+	openTime, err := parseImportTime(text("datetime"), loc)
+	if err != nil {
+		return importRow{}, false
+	}
+	var row importRow
+	var ok1, ok2, ok3, ok4 bool
+	row.openTime = openTime
+	row.open, ok1 = price("open")
+	row.high, ok2 = price("high")
+	row.low, ok3 = price("low")
+	row.close, ok4 = price("close")
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return importRow{}, false
+	}
+	row.volume, _ = strconv.ParseFloat(text("volume"), 64)
+	return row, true
+}
+
+// parseImportTime parses unix seconds/milliseconds or a textual timestamp in loc
+func parseImportTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty timestamp")
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && len(s) >= 9 {
+		if n > 1e12 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
+
+// buildImportCandles validates rows and returns candles sorted by time without duplicates
+func buildImportCandles(rows []importRow) ([]Candlestick, int) {
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].openTime.Before(rows[j].openTime) })
+
+	candles := make([]Candlestick, 0, len(rows))
+	invalid := 0
+	for _, r := range rows {
+		if r.open <= 0 || r.high <= 0 || r.low <= 0 || r.close <= 0 ||
+			r.high < max(r.open, r.close) || r.low > min(r.open, r.close) {
+			invalid++
+			continue
+		}
+		c := Candlestick{OpenTime: r.openTime, Open: r.open, High: r.high, Low: r.low, Close: r.close, Volume: r.volume}
+		// Duplicate timestamps: the later row wins
+		if n := len(candles); n > 0 && candles[n-1].OpenTime.Equal(c.OpenTime) {
+			candles[n-1] = c
+			invalid++
+			continue
+		}
+		candles = append(candles, c)
+	}
+	return candles, invalid
+}
+
+// detectImportInterval returns the most common bar spacing as a supported interval
+func detectImportInterval(candles []Candlestick) (BinanceInterval, error) {
+	counts := make(map[time.Duration]int)
+	for i := 1; i < len(candles); i++ {
+		counts[candles[i].OpenTime.Sub(candles[i-1].OpenTime)]++
+	}
+	var spacing time.Duration
+	best := 0
+	for d, n := range counts {
+		if n > best || (n == best && d < spacing) {
+			spacing, best = d, n
+		}
+	}
+
+	for _, interval := range importIntervals {
+		if IntervalDuration(interval) == spacing {
+			return interval, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported bar spacing %s (supported: 1m, 5m, 15m, 30m, 1h, 4h, 1d, 1w)", spacing)
+}
+
+// decimalTracker records the largest number of decimals quoted in price fields
+type decimalTracker struct {
+	decimals int
+}
+
+// observe records the decimals of a numeric string
+func (d *decimalTracker) observe(s string) {
+	if i := strings.LastIndex(s, "."); i >= 0 {
+		n := len(s) - i - 1
+		if n > d.decimals {
+			d.decimals = min(n, maxImportDecimals)
+		}
+	}
+}
+
+// precision returns the price precision implied by the observed decimals
+func (d *decimalTracker) precision() InstrumentPrecision {
+	return InstrumentPrecision{
+		TickSize: math.Pow(10, -float64(d.decimals)),
+		Decimals: d.decimals,
+	}
+}
+
+// ImportCalendar guesses the trading calendar of imported candles
+// Bars on weekends mean a 24/7 market; otherwise gaps are judged like forex
+func ImportCalendar(candles []Candlestick) TradingCalendar {
+	for _, c := range candles {
+		if isWeekend(c.OpenTime.UTC()) {
+			return CalendarForMarket(MarketCrypto, "")
+		}
+	}
+	return CalendarForMarket(MarketForex, "")
+}
+
+// ImportSpec is the parsed caption of an uploaded candle file
+type ImportSpec struct {
+	Symbol  string
+	Mode    TradingMode
+	Options ImportOptions
+}
+
+// ParseImportSpec parses a document caption such as "EURUSD swing tz=Europe/London cols=date,time,open,high,low,close"
+// The symbol defaults to the file name without extension and the mode to intraday
+func ParseImportSpec(caption, fileName string) (ImportSpec, error) {
+	spec := ImportSpec{Mode: TradingModeIntraday}
+
+	for _, token := range strings.Fields(caption) {
+		lower := strings.ToLower(token)
+		switch {
+		case strings.HasPrefix(lower, "/"):
+			continue // command prefix such as /import
+		case lower == "scalping" || lower == "sc":
+			spec.Mode = TradingModeScalping
+		case lower == "intraday" || lower == "int":
+			spec.Mode = TradingModeIntraday
+		case lower == "swing" || lower == "sw":
+			spec.Mode = TradingModeSwing
+		case strings.HasPrefix(lower, "tz="):
+			loc, err := time.LoadLocation(token[3:])
+			if err != nil {
+				return spec, fmt.Errorf("timezone tidak dikenal: %s", token[3:])
+			}
+			spec.Options.Location = loc
+		case strings.HasPrefix(lower, "cols="):
+			cols, err := ParseCSVColumns(token[5:])
+			if err != nil {
+				return spec, err
+			}
+			spec.Options.Columns = &cols
+		case strings.HasPrefix(lower, "format="):
+			switch f := ImportFormat(lower[7:]); f {
+			case ImportFormatCSV, ImportFormatMetaTrader, ImportFormatJSON:
+				spec.Options.Format = f
+			default:
+				return spec, fmt.Errorf("format tidak dikenal: %s (csv, mt, json)", lower[7:])
+			}
+		default:
+			if spec.Symbol == "" {
+				spec.Symbol = strings.ToUpper(token)
+			}
+		}
+	}
+
+	if spec.Symbol == "" {
+		base := filepath.Base(fileName)
+		spec.Symbol = strings.ToUpper(strings.TrimSuffix(base, filepath.Ext(base)))
+	}
+	if spec.Symbol == "" {
+		spec.Symbol = "IMPORTED"
+	}
+	return spec, nil
+}
+
+// ParseCSVColumns parses a column list such as "date,time,open,high,low,close,volume"
+// Unknown names (e.g. "skip" or "_") mark ignored columns
+func ParseCSVColumns(list string) (CSVColumns, error) {
+	cols, ok := parseCSVHeader(strings.Split(list, ","))
+	if !ok || cols.Date < 0 || cols.Open < 0 || cols.High < 0 || cols.Low < 0 || cols.Close < 0 {
+		return CSVColumns{}, fmt.Errorf("cols harus berisi time/date, open, high, low, close (contoh: cols=time,open,high,low,close,volume)")
+	}
+	return cols, nil
+}
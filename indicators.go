@@ -0,0 +1,372 @@
+package main
+
+import "math"
+
+// Technical indicators shared by the analysis summaries and the chart renderer
+// Every function returns a series as long as its input; values are NaN until the
+// indicator has enough data (warm-up), matching how TradingView leaves them blank
+
+// Default indicator periods
+const (
+	rsiPeriod        = 14
+	atrPeriod        = 14
+	macdFast         = 12
+	macdSlow         = 26
+	macdSignal       = 9
+	bollingerPeriod  = 20
+	bollingerStdDev  = 2.0
+	stochasticK      = 14
+	stochasticSmooth = 3
+	stochasticD      = 3
+	dmiPeriod        = 14
+)
+
+// Closes returns the close prices of candles
+func Closes(candles []Candlestick) []float64 {
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	return closes
+}
+
+// Last returns the last value of a series (NaN when empty)
+func Last(series []float64) float64 {
+	if len(series) == 0 {
+		return math.NaN()
+	}
+	return series[len(series)-1]
+}
+
+// lastOrZero returns the last value of a series, or 0 while it is still warming up
+func lastOrZero(series []float64) float64 {
+	if v := Last(series); !math.IsNaN(v) {
+		return v
+	}
+	return 0
+}
+
+// nanSeries returns a series of n NaN values
+func nanSeries(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
+
+// firstValid returns the index of the first non-NaN value (len(values) if none)
+func firstValid(values []float64) int {
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			return i
+		}
+	}
+	return len(values)
+}
+
+// SMA is the simple moving average
+func SMA(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period < 1 {
+		return out
+	}
+	start := firstValid(values)
+	sum := 0.0
+	for i := start; i < len(values); i++ {
+		sum += values[i]
+		if i-start >= period {
+			sum -= values[i-period]
+		}
+		if i-start >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// EMA is the exponential moving average (alpha = 2/(period+1)) seeded with the SMA of the first period values
+func EMA(values []float64, period int) []float64 {
+	return smoothSeeded(values, period, 2/float64(period+1))
+}
+
+// RMA is Wilder's smoothing (alpha = 1/period) seeded with an SMA, used by RSI, ATR and ADX
+func RMA(values []float64, period int) []float64 {
+	return smoothSeeded(values, period, 1/float64(period))
+}
+
+// smoothSeeded applies exponential smoothing with the given alpha after an SMA seed
+// Leading NaN values (e.g., another indicator's warm-up) are skipped
+func smoothSeeded(values []float64, period int, alpha float64) []float64 {
+	out := nanSeries(len(values))
+	if period < 1 {
+		return out
+	}
+	start := firstValid(values)
+	if len(values)-start < period {
+		return out
+	}
+
+	sum := 0.0
+	for i := start; i < start+period; i++ {
+		sum += values[i]
+	}
+	prev := sum / float64(period)
+	out[start+period-1] = prev
+	for i := start + period; i < len(values); i++ {
+		prev = alpha*values[i] + (1-alpha)*prev
+		out[i] = prev
+	}
+	return out
+}
+
+// WMA is the linearly weighted moving average (newest value weighted by period)
+func WMA(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period < 1 {
+		return out
+	}
+	start := firstValid(values)
+	denom := float64(period*(period+1)) / 2
+	for i := start + period - 1; i < len(values); i++ {
+		sum := 0.0
+		for j := 0; j < period; j++ {
+			sum += values[i-j] * float64(period-j)
+		}
+		out[i] = sum / denom
+	}
+	return out
+}
+
+// RSI is Wilder's relative strength index on close-to-close changes
+func RSI(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if len(values) <= period {
+		return out
+	}
+
+	gains := nanSeries(len(values))
+	losses := nanSeries(len(values))
+	for i := 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		gains[i] = math.Max(change, 0)
+		losses[i] = math.Max(-change, 0)
+	}
+	avgGain := RMA(gains, period)
+	avgLoss := RMA(losses, period)
+
+	for i := range values {
+		if math.IsNaN(avgGain[i]) {
+			continue
+		}
+		switch {
+		case avgLoss[i] == 0:
+			out[i] = 100
+		case avgGain[i] == 0:
+			out[i] = 0
+		default:
+			out[i] = 100 - 100/(1+avgGain[i]/avgLoss[i])
+		}
+	}
+	return out
+}
+
+// TrueRange is max(high-low, |high-prevClose|, |low-prevClose|); the first bar uses high-low
+func TrueRange(candles []Candlestick) []float64 {
+	tr := make([]float64, len(candles))
+	for i, c := range candles {
+		tr[i] = c.High - c.Low
+		if i > 0 {
+			prevClose := candles[i-1].Close
+			tr[i] = math.Max(tr[i], math.Max(math.Abs(c.High-prevClose), math.Abs(c.Low-prevClose)))
+		}
+	}
+	return tr
+}
+
+// ATR is Wilder's average true range
+func ATR(candles []Candlestick, period int) []float64 {
+	return RMA(TrueRange(candles), period)
+}
+
+// MACDResult holds the MACD line, its signal line and the histogram
+type MACDResult struct {
+	MACD      []float64
+	Signal    []float64
+	Histogram []float64
+}
+
+// MACD computes EMA(fast) - EMA(slow), its EMA(signal) and the difference
+func MACD(values []float64, fast, slow, signal int) MACDResult {
+	fastEMA := EMA(values, fast)
+	slowEMA := EMA(values, slow)
+
+	result := MACDResult{
+		MACD:      nanSeries(len(values)),
+		Histogram: nanSeries(len(values)),
+	}
+	for i := range values {
+		result.MACD[i] = fastEMA[i] - slowEMA[i] // NaN while either is warming up
+	}
+	result.Signal = EMA(result.MACD, signal)
+	for i := range values {
+		result.Histogram[i] = result.MACD[i] - result.Signal[i]
+	}
+	return result
+}
+
+// BollingerResult holds the bands around an SMA
+type BollingerResult struct {
+	Upper  []float64
+	Middle []float64
+	Lower  []float64
+}
+
+// BollingerBands computes SMA(period) ± mult × population standard deviation
+func BollingerBands(values []float64, period int, mult float64) BollingerResult {
+	result := BollingerResult{
+		Upper:  nanSeries(len(values)),
+		Middle: SMA(values, period),
+		Lower:  nanSeries(len(values)),
+	}
+	for i := range values {
+		mean := result.Middle[i]
+		if math.IsNaN(mean) {
+			continue
+		}
+		variance := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			d := values[j] - mean
+			variance += d * d
+		}
+		dev := mult * math.Sqrt(variance/float64(period))
+		result.Upper[i] = mean + dev
+		result.Lower[i] = mean - dev
+	}
+	return result
+}
+
+// StochasticResult holds the smoothed %K and its %D signal
+type StochasticResult struct {
+	K []float64
+	D []float64
+}
+
+// Stochastic computes the slow stochastic: raw %K over kPeriod smoothed by SMA(smooth), %D = SMA(dPeriod) of %K
+func Stochastic(candles []Candlestick, kPeriod, smooth, dPeriod int) StochasticResult {
+	raw := nanSeries(len(candles))
+	if kPeriod < 1 {
+		return StochasticResult{K: raw, D: nanSeries(len(candles))}
+	}
+	for i := kPeriod - 1; i < len(candles); i++ {
+		highest, lowest := candles[i].High, candles[i].Low
+		for j := i - kPeriod + 1; j < i; j++ {
+			highest = math.Max(highest, candles[j].High)
+			lowest = math.Min(lowest, candles[j].Low)
+		}
+		if highest == lowest {
+			raw[i] = 50
+			continue
+		}
+		raw[i] = (candles[i].Close - lowest) / (highest - lowest) * 100
+	}
+
+	k := SMA(raw, smooth)
+	return StochasticResult{K: k, D: SMA(k, dPeriod)}
+}
+
+// DMIResult holds Wilder's directional movement indicators
+type DMIResult struct {
+	PlusDI  []float64
+	MinusDI []float64
+	ADX     []float64
+}
+
+// DMI computes +DI, -DI and ADX with Wilder smoothing
+func DMI(candles []Candlestick, period int) DMIResult {
+	n := len(candles)
+	result := DMIResult{PlusDI: nanSeries(n), MinusDI: nanSeries(n), ADX: nanSeries(n)}
+	if n <= period {
+		return result
+	}
+
+	plusDM := nanSeries(n)
+	minusDM := nanSeries(n)
+	tr := nanSeries(n)
+	trueRange := TrueRange(candles)
+	for i := 1; i < n; i++ {
+		up := candles[i].High - candles[i-1].High
+		down := candles[i-1].Low - candles[i].Low
+		plusDM[i], minusDM[i] = 0, 0
+		if up > down && up > 0 {
+			plusDM[i] = up
+		}
+		if down > up && down > 0 {
+			minusDM[i] = down
+		}
+		tr[i] = trueRange[i]
+	}
+
+	smoothTR := RMA(tr, period)
+	smoothPlus := RMA(plusDM, period)
+	smoothMinus := RMA(minusDM, period)
+	dx := nanSeries(n)
+	for i := range candles {
+		if math.IsNaN(smoothTR[i]) || smoothTR[i] == 0 {
+			continue
+		}
+		result.PlusDI[i] = smoothPlus[i] / smoothTR[i] * 100
+		result.MinusDI[i] = smoothMinus[i] / smoothTR[i] * 100
+		sum := result.PlusDI[i] + result.MinusDI[i]
+		dx[i] = 0
+		if sum > 0 {
+			dx[i] = math.Abs(result.PlusDI[i]-result.MinusDI[i]) / sum * 100
+		}
+	}
+	result.ADX = RMA(dx, period)
+	return result
+}
+
+// OBV is on-balance volume, starting at 0 on the first candle
+func OBV(candles []Candlestick) []float64 {
+	obv := make([]float64, len(candles))
+	for i := 1; i < len(candles); i++ {
+		obv[i] = obv[i-1]
+		switch {
+		case candles[i].Close > candles[i-1].Close:
+			obv[i] += candles[i].Volume
+		case candles[i].Close < candles[i-1].Close:
+			obv[i] -= candles[i].Volume
+		}
+	}
+	return obv
+}
+
+// VWAP is the volume-weighted typical price, reset at each anchor bucket (e.g., Interval1d for
+// a daily session VWAP); an empty anchor accumulates over the whole series
+// Candles without volume fall back to the running typical-price average
+func VWAP(candles []Candlestick, anchor BinanceInterval) []float64 {
+	out := make([]float64, len(candles))
+	var pv, vol, typicalSum float64
+	var count int
+	var bucket int64
+	for i, c := range candles {
+		if anchor != "" {
+			if b := resampleBucketStart(c.OpenTime, anchor, 0).Unix(); i == 0 || b != bucket {
+				bucket = b
+				pv, vol, typicalSum, count = 0, 0, 0, 0
+			}
+		}
+		typical := (c.High + c.Low + c.Close) / 3
+		pv += typical * c.Volume
+		vol += c.Volume
+		typicalSum += typical
+		count++
+		if vol > 0 {
+			out[i] = pv / vol
+		} else {
+			out[i] = typicalSum / float64(count)
+		}
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// StockCharts "Moving Averages - Simple and Exponential" worksheet closes
+var stockChartsMACloses = []float64{
+	22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+	22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+}
+
+// StockCharts "Relative Strength Index" worksheet closes (Wilder's smoothing)
+var stockChartsRSICloses = []float64{
+	44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+	45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+	43.42, 42.66, 43.13,
+}
+
+// bar builds an hourly candle for the hand-worked examples
+func bar(hour int, high, low, close, volume float64) Candlestick {
+	open := time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC)
+	return Candlestick{OpenTime: open, High: high, Low: low, Close: close, Volume: volume, CloseTime: open.Add(time.Hour - time.Millisecond)}
+}
+
+// Hand-worked OHLC: true ranges 2, 2, 1.5, 1.5, 2.8
+var workedCandles = []Candlestick{
+	bar(0, 10, 8, 9, 0),
+	bar(1, 11, 9, 10.5, 0),
+	bar(2, 12, 10.5, 11, 0),
+	bar(3, 11.5, 10, 10.2, 0),
+	bar(4, 13, 10.5, 12.8, 0),
+}
+
+// rampCandles rises by 1 every bar with a range of 1 and closes mid-bar
+func rampCandles(n int) []Candlestick {
+	candles := make([]Candlestick, n)
+	for i := range candles {
+		candles[i] = bar(i, 10+float64(i), 9+float64(i), 9.5+float64(i), 1)
+	}
+	return candles
+}
+
+// ramp returns 0, 1, ..., n-1
+func ramp(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	return values
+}
+
+// repeat returns n copies of v
+func repeat(v float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = v
+	}
+	return values
+}
+
+func TestIndicatorReferenceValues(t *testing.T) {
+	macd := MACD(ramp(40), macdFast, macdSlow, macdSignal)
+	bands := BollingerBands([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+	stoch := Stochastic(workedCandles, 3, 2, 2)
+	dmi := DMI(rampCandles(30), dmiPeriod)
+	vwap := VWAP([]Candlestick{bar(0, 10, 8, 9, 2), bar(12, 12, 9, 12, 1), bar(24, 13, 11, 12, 5)}, Interval1d)
+
+	tests := []struct {
+		name string
+		got  []float64
+		from int // first index with a value; earlier ones must be NaN
+		want []float64
+		tol  float64
+	}{
+		// StockCharts publishes these rounded to cents
+		{"SMA(10)", SMA(stockChartsMACloses, 10), 9, []float64{
+			22.22, 22.21, 22.23, 22.26, 22.30, 22.42, 22.61, 22.77, 22.91, 23.08, 23.21,
+			23.38, 23.52, 23.65, 23.71, 23.68, 23.61, 23.51, 23.43, 23.28, 23.13,
+		}, 0.01},
+		{"EMA(10)", EMA(stockChartsMACloses, 10), 9, []float64{
+			22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34,
+			23.43, 23.51, 23.54, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+		}, 0.01},
+		// The StockCharts worksheet rounds its average gain/loss, hence the wider tolerance
+		{"RSI(14)", RSI(stockChartsRSICloses, 14), 14, []float64{
+			70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+			54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+		}, 0.1},
+		{"WMA(3)", WMA([]float64{1, 2, 3, 4}, 3), 2, []float64{14.0 / 6, 20.0 / 6}, 1e-9},
+		// Seed (2+2+1.5)/3, then Wilder: (prev*2 + TR)/3
+		{"ATR(3)", ATR(workedCandles, 3), 2, []float64{11.0 / 6, 31.0 / 18, (62.0/18 + 2.8) / 3}, 1e-9},
+		// An EMA seeded with its SMA lags a ramp by exactly (period-1)/2: 12.5 - 5.5 = 7
+		{"MACD line", macd.MACD, 25, repeat(7, 15), 1e-9},
+		{"MACD signal", macd.Signal, 33, repeat(7, 7), 1e-9},
+		{"MACD histogram", macd.Histogram, 33, repeat(0, 7), 1e-9},
+		// Population standard deviation of 2,4,4,4,5,5,7,9 is 2 around a mean of 5
+		{"Bollinger upper", bands.Upper, 7, []float64{9}, 1e-9},
+		{"Bollinger middle", bands.Middle, 7, []float64{5}, 1e-9},
+		{"Bollinger lower", bands.Lower, 7, []float64{1}, 1e-9},
+		// Raw %K(3): 75, 40, 93.33; %K = SMA(2) of raw, %D = SMA(2) of %K
+		{"Stochastic %K", stoch.K, 3, []float64{57.5, 200.0 / 3}, 1e-9},
+		{"Stochastic %D", stoch.D, 4, []float64{(57.5 + 200.0/3) / 2}, 1e-9},
+		// Every bar: +DM 1, -DM 0, TR 1.5
+		{"+DI(14)", dmi.PlusDI, 14, repeat(200.0/3, 16), 1e-9},
+		{"-DI(14)", dmi.MinusDI, 14, repeat(0, 16), 1e-9},
+		{"ADX(14)", dmi.ADX, 27, repeat(100, 3), 1e-9},
+		// Typical prices 9 (x2) and 11 (x1), then a new day resets to 12
+		{"VWAP(1d)", vwap, 0, []float64{9, 29.0 / 3, 12}, 1e-9},
+	}
+
+	for _, tt := range tests {
+		if len(tt.got) != tt.from+len(tt.want) {
+			t.Errorf("%s: got %d values, want %d", tt.name, len(tt.got), tt.from+len(tt.want))
+			continue
+		}
+		for i := 0; i < tt.from; i++ {
+			if !math.IsNaN(tt.got[i]) {
+				t.Errorf("%s[%d] = %v during warm-up, want NaN", tt.name, i, tt.got[i])
+			}
+		}
+		for i, want := range tt.want {
+			if got := tt.got[tt.from+i]; !(math.Abs(got-want) <= tt.tol) {
+				t.Errorf("%s[%d] = %.4f, want %.4f", tt.name, tt.from+i, got, want)
+			}
+		}
+	}
+}
+
+func TestIndicatorInvalidPeriods(t *testing.T) {
+	closes := Closes(workedCandles)
+	series := map[string][]float64{
+		"SMA(0)":            SMA(closes, 0),
+		"WMA(0)":            WMA(closes, 0),
+		"WMA(-2)":           WMA(closes, -2),
+		"EMA(0)":            EMA(closes, 0),
+		"Stochastic(0) %K":  Stochastic(workedCandles, 0, 3, 3).K,
+		"Stochastic(-1) %D": Stochastic(workedCandles, -1, 3, 3).D,
+	}
+	for name, got := range series {
+		if len(got) != len(closes) {
+			t.Errorf("%s: got %d values, want %d", name, len(got), len(closes))
+			continue
+		}
+		for i, v := range got {
+			if !math.IsNaN(v) {
+				t.Errorf("%s[%d] = %v, want NaN", name, i, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KrakenBaseURL is the Kraken public REST API
+const KrakenBaseURL = "https://api.kraken.com"
+
+// krakenMaxCandles is the number of most recent candles /0/public/OHLC returns (no older paging)
+const krakenMaxCandles = 720
+
+// krakenIntervals maps intervals to OHLC interval minutes
+// Note: weekly candles are anchored to Thursday like the Unix epoch
+var krakenIntervals = map[BinanceInterval]int{
+	Interval1m:  1,
+	Interval5m:  5,
+	Interval15m: 15,
+	Interval30m: 30,
+	Interval1h:  60,
+	Interval4h:  240,
+	Interval1d:  1440,
+	Interval1w:  10080,
+}
+
+// krakenAssets maps common asset codes to Kraken's (BTC -> XBT)
+var krakenAssets = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+// KrakenProvider serves Kraken spot candles
+type KrakenProvider struct {
+	BaseURL string // overridable for recorded fixtures
+}
+
+// NewKrakenProvider creates a provider for the public Kraken API
+func NewKrakenProvider() KrakenProvider {
+	return KrakenProvider{BaseURL: KrakenBaseURL}
+}
+
+// Name returns the provider name
+func (KrakenProvider) Name() string { return "Kraken" }
+
+// Market returns MarketCrypto
+func (KrakenProvider) Market() Market { return MarketCrypto }
+
+// NormalizeSymbol converts input to a Kraken pair (e.g., "btc/usd" -> "XBTUSD")
+func (KrakenProvider) NormalizeSymbol(input string) (string, string, error) {
+	pair, err := ParseCryptoPair(input)
+	if err != nil {
+		return "", "", err
+	}
+	return krakenAsset(pair.Base) + krakenAsset(pair.Quote), pair.String(), nil
+}
+
+// krakenAsset returns the Kraken code of an asset
+func krakenAsset(asset string) string {
+	if code, ok := krakenAssets[asset]; ok {
+		return code
+	}
+	return asset
+}
+
+// get calls a public endpoint and returns the result payload
+// "Unknown asset pair" errors are reported as errSymbolNotListed
+func (p KrakenProvider) get(ctx context.Context, path string, query url.Values) (json.RawMessage, error) {
+	body, _, err := DefaultHTTPClient.Get(ctx, fmt.Sprintf("%s%s?%s", p.BaseURL, path, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Kraken API error: %w", err)
+	}
+	return parseKrakenResponse(body)
+}
+
+// parseKrakenResponse unwraps the {"error": [...], "result": {...}} envelope
+func parseKrakenResponse(body []byte) (json.RawMessage, error) {
+	var resp struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse Kraken JSON: %w", err)
+	}
+	if len(resp.Error) > 0 {
+		msg := strings.Join(resp.Error, "; ")
+		if strings.Contains(msg, "Unknown asset pair") {
+			return nil, fmt.Errorf("Kraken: %s: %w", msg, errSymbolNotListed)
+		}
+		return nil, fmt.Errorf("Kraken error: %s", msg)
+	}
+	return resp.Result, nil
+}
+
+// FetchCandles fetches up to krakenMaxCandles recent candles
+func (p KrakenProvider) FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	minutes, ok := krakenIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("Kraken does not support interval %s", interval)
+	}
+	if limit < 1 {
+		limit = 200
+	}
+
+	query := url.Values{}
+	query.Set("pair", symbol)
+	query.Set("interval", strconv.Itoa(minutes))
+	result, err := p.get(ctx, "/0/public/OHLC", query)
+	if err != nil {
+		return nil, err
+	}
+
+	candles, err := parseKrakenOHLC(result, interval)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
+
+// parseKrakenOHLC parses result.<pair>: [time, open, high, low, close, vwap, volume, count], oldest first
+// The trade count is not copied to Trades: without taker volume it would skew AnalyzeOrderFlow
+func parseKrakenOHLC(result json.RawMessage, interval BinanceInterval) ([]Candlestick, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Kraken OHLC: %w", err)
+	}
+
+	var candles []Candlestick
+	for key, value := range raw {
+		if key == "last" {
+			continue
+		}
+		var rows [][]any
+		if err := json.Unmarshal(value, &rows); err != nil {
+			return nil, fmt.Errorf("failed to parse Kraken OHLC rows: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) < 7 {
+				continue
+			}
+			ts, _ := row[0].(float64)
+			field := func(i int) float64 {
+				s, _ := row[i].(string)
+				v, _ := strconv.ParseFloat(s, 64)
+				return v
+			}
+			candle := exchangeCandle(time.Unix(int64(ts), 0), interval, field(1), field(2), field(3), field(4), field(6))
+			candle.QuoteVolume = field(5) * candle.Volume // vwap x volume
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+// GetCurrentPrice returns the last trade price from /0/public/Ticker
+func (p KrakenProvider) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	query := url.Values{}
+	query.Set("pair", symbol)
+	result, err := p.get(ctx, "/0/public/Ticker", query)
+	if err != nil {
+		return 0, err
+	}
+	return parseKrakenTicker(result, symbol)
+}
+
+// parseKrakenTicker reads the last trade price "c"[0] of the only pair in the result
+func parseKrakenTicker(result json.RawMessage, symbol string) (float64, error) {
+	var raw map[string]struct {
+		C []string `json:"c"`
+	}
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return 0, fmt.Errorf("failed to parse Kraken ticker: %w", err)
+	}
+	for _, t := range raw {
+		if len(t.C) > 0 {
+			return strconv.ParseFloat(t.C[0], 64)
+		}
+	}
+	return 0, fmt.Errorf("Kraken: no ticker for %s: %w", symbol, errSymbolNotListed)
+}
+
+// ValidateSymbol checks the pair through its ticker
+func (p KrakenProvider) ValidateSymbol(ctx context.Context, symbol string) (bool, error) {
+	return validateByPrice(ctx, p, symbol)
+}
+
+// SupportedIntervals returns the native Kraken OHLC intervals
+func (KrakenProvider) SupportedIntervals() []BinanceInterval {
+	return []BinanceInterval{Interval1m, Interval5m, Interval15m, Interval30m, Interval1h, Interval4h, Interval1d, Interval1w}
+}
+
+// Precision borrows the Binance tick size of the same pair
+func (KrakenProvider) Precision(symbol string) InstrumentPrecision {
+	pair, _ := ParseCryptoPair(symbol)
+	for common, code := range krakenAssets {
+		if pair.Base == code {
+			pair.Base = common
+		}
+		if pair.Quote == code {
+			pair.Quote = common
+		}
+	}
+	return cryptoPairPrecision(pair)
+}
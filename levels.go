@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Support/resistance engine: swing pivots of every timeframe are scored by timeframe,
+// recency and volume, then clustered into levels within a tolerance based on each pivot's ATR
+
+const (
+	srToleranceATR = 0.25 // a pivot merges within this fraction of its own timeframe's ATR
+	srMaxLevels    = 8    // ranked levels kept
+	srMinVolume    = 0.5  // clamp for the relative volume factor
+	srMaxVolume    = 3.0
+)
+
+// Colors for support/resistance bands
+var (
+	colorSupport    = color.NRGBA{R: 129, G: 199, B: 132, A: 35}
+	colorResistance = color.NRGBA{R: 229, G: 115, B: 115, A: 35}
+)
+
+// SRLevel is a clustered support or resistance level
+type SRLevel struct {
+	Kind       string  // SUPPORT or RESISTANCE relative to the current price
+	Price      float64 // score-weighted center
+	Low        float64 // lowest pivot in the cluster
+	High       float64 // highest pivot in the cluster
+	Touches    int
+	Timeframes []BinanceInterval // timeframes with a pivot in the cluster, smallest first
+	LastTouch  time.Time
+	Score      float64
+}
+
+// srPivot is a swing point weighted for clustering
+type srPivot struct {
+	price     float64
+	score     float64
+	time      time.Time
+	interval  BinanceInterval
+	tolerance float64 // merge distance from the ATR of the pivot's timeframe
+}
+
+// DetectSupportResistance clusters the swing points of all summaries into ranked levels
+// A pivot scores timeframeWeight x recency x volume: higher timeframes count more, recency
+// runs from 0.5 (start of its window) to 1 (latest candle) and volume is the swing candle's
+// relative volume clamped to [0.5, 3] (1 without volume data)
+func DetectSupportResistance(summaries []CandleDataSummary) []SRLevel {
+	var pivots []srPivot
+	minDur, price := time.Duration(0), 0.0
+	for _, s := range summaries {
+		if s.SMC == nil {
+			continue
+		}
+		if dur := IntervalDuration(s.Interval); minDur == 0 || dur < minDur {
+			minDur = dur
+			price = s.Close
+		}
+	}
+	if minDur == 0 || price == 0 {
+		return nil
+	}
+
+	for _, s := range summaries {
+		if s.SMC == nil {
+			continue
+		}
+		weight := 1 + math.Log2(float64(IntervalDuration(s.Interval))/float64(minDur))/2
+		span := s.EndTime.Sub(s.StartTime)
+		tolerance := s.ATR * srToleranceATR
+		if tolerance == 0 {
+			tolerance = price * 0.001
+		}
+		for _, sw := range s.SMC.Swings {
+			recency := 1.0
+			if span > 0 {
+				recency = 0.5 + 0.5*float64(sw.Time.Sub(s.StartTime))/float64(span)
+			}
+			volume := 1.0
+			if sw.RelVolume > 0 {
+				volume = math.Min(math.Max(sw.RelVolume, srMinVolume), srMaxVolume)
+			}
+			pivots = append(pivots, srPivot{price: sw.Price, score: weight * recency * volume, time: sw.Time, interval: s.Interval, tolerance: tolerance})
+		}
+	}
+
+	levels := clusterPivots(pivots)
+	for i := range levels {
+		levels[i].Kind = "SUPPORT"
+		if levels[i].Price > price {
+			levels[i].Kind = "RESISTANCE"
+		}
+	}
+
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Score > levels[j].Score })
+	if len(levels) > srMaxLevels {
+		levels = levels[:srMaxLevels]
+	}
+	// Nearest to price first
+	sort.SliceStable(levels, func(i, j int) bool {
+		return math.Abs(levels[i].Price-price) < math.Abs(levels[j].Price-price)
+	})
+	return levels
+}
+
+// clusterPivots merges price-sorted pivots while each stays within its own tolerance of the cluster center
+func clusterPivots(pivots []srPivot) []SRLevel {
+	sort.Slice(pivots, func(i, j int) bool { return pivots[i].price < pivots[j].price })
+
+	var levels []SRLevel
+	var cluster []srPivot
+	var weighted, total float64
+	flush := func() {
+		if len(cluster) == 0 {
+			return
+		}
+		level := SRLevel{Price: weighted / total, Low: cluster[0].price, High: cluster[len(cluster)-1].price, Touches: len(cluster), Score: total}
+		seen := map[BinanceInterval]bool{}
+		for _, p := range cluster {
+			if !seen[p.interval] {
+				seen[p.interval] = true
+				level.Timeframes = append(level.Timeframes, p.interval)
+			}
+			if p.time.After(level.LastTouch) {
+				level.LastTouch = p.time
+			}
+		}
+		sort.Slice(level.Timeframes, func(i, j int) bool {
+			return IntervalDuration(level.Timeframes[i]) < IntervalDuration(level.Timeframes[j])
+		})
+		levels = append(levels, level)
+		cluster, weighted, total = nil, 0, 0
+	}
+
+	for _, p := range pivots {
+		if len(cluster) > 0 && p.price-weighted/total > p.tolerance {
+			flush()
+		}
+		cluster = append(cluster, p)
+		weighted += p.price * p.score
+		total += p.score
+	}
+	flush()
+	return levels
+}
+
+// srLabels numbers levels per side in distance order (S1, S2, ... and R1, R2, ...)
+func srLabels(levels []SRLevel) []string {
+	labels := make([]string, len(levels))
+	support, resistance := 0, 0
+	for i, l := range levels {
+		if l.Kind == "SUPPORT" {
+			support++
+			labels[i] = fmt.Sprintf("S%d", support)
+		} else {
+			resistance++
+			labels[i] = fmt.Sprintf("R%d", resistance)
+		}
+	}
+	return labels
+}
+
+// FormatSupportResistanceForAI formats the ranked levels as a prompt section
+func FormatSupportResistanceForAI(levels []SRLevel, prec InstrumentPrecision) string {
+	if len(levels) == 0 {
+		return ""
+	}
+	p := prec.Format
+	labels := srLabels(levels)
+
+	var sb strings.Builder
+	sb.WriteString("--- SUPPORT / RESISTANCE LEVELS (clustered swing pivots, nearest first) ---\n")
+	for i, l := range levels {
+		var tfs []string
+		for _, tf := range l.Timeframes {
+			tfs = append(tfs, string(tf))
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s (zone %s-%s) | Score %.1f | %d touches | TF %s | Last %s\n",
+			labels[i], p(l.Price), p(l.Low), p(l.High), l.Score, l.Touches, strings.Join(tfs, ","), l.LastTouch.UTC().Format("2006-01-02 15:04")))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// SRAnnotations draws each level as a band across the chart
+func SRAnnotations(levels []SRLevel) []ChartAnnotation {
+	labels := srLabels(levels)
+	out := make([]ChartAnnotation, 0, len(levels))
+	for i, l := range levels {
+		c := colorSupport
+		if l.Kind == "RESISTANCE" {
+			c = colorResistance
+		}
+		out = append(out, ChartAnnotation{Kind: AnnotationZone, Label: labels[i], Source: SourceAlgo,
+			Top: l.High, Bottom: l.Low, Color: c})
+	}
+	return out
+}
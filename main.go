@@ -3,13 +3,14 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
-	"strconv"
 
 	"strings"
 	"sync"
@@ -31,6 +32,9 @@ const (
 	ModeAutoIntraday                     // /autoint - auto fetch charts for intraday
 )
 
+// autoFetchTimeout bounds the market data requests of one auto analysis
+const autoFetchTimeout = 2 * time.Minute
+
 var (
 	// userMode stores the user's selected mode
 	userMode sync.Map // map[int64]AnalysisMode
@@ -188,6 +192,11 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// 3. Start Binance real-time streams (kline/ticker subscriptions are added on demand)
+	go DefaultBinanceStream.Run(ctx)
+	go DefaultLiquidationTracker.Run(ctx)
+	go DefaultSymbolCatalog.Run(ctx)
+
 	// === Commands ===
 	var handlePhoto func(c tele.Context) error
 	
@@ -246,17 +255,29 @@ Selamat datang! Saya adalah asisten trading AI Anda.
    • /analyst - <b>Mode Standard</b> (Swing/Intraday)
    • /scalping - <b>Mode Scalping</b> (M1/M5)
    
-<b>2. Mode Auto CRYPTO (Binance):</b>
+<b>2. Mode Auto CRYPTO (Binance, Bybit, OKX, Coinbase, Kraken):</b>
    • /autosc BTCUSDT - <b>Auto Scalping</b> (5m,15m,1H,4H,1D)
    • /autosw BTCUSDT - <b>Auto Swing</b> (5m,15m,1H,4H,1D,1W)
    • /autoint BTCUSDT - <b>Auto Intraday</b> (5m,15m,1H,4H,1D,1W)
+   • Pilih exchange: <code>/autosc BTCUSDT okx</code> (default Binance, fallback otomatis)
 
 <b>3. Mode Auto FOREX (Yahoo Finance):</b>
-   • /fxsc EURUSD - <b>Forex Scalping</b> (5m,15m,1H,1D)
-   • /fxsw GBPJPY - <b>Forex Swing</b> (15m,1H,1D,1W)
-   • /fxint XAUUSD - <b>Forex Intraday</b> (5m,15m,1H,1D)
-
-<b>4. Kirim Chart Manual:</b>
+   • /fxsc EURUSD - <b>Forex Scalping</b> (5m,15m,1H,4H,1D)
+   • /fxsw GBPJPY - <b>Forex Swing</b> (15m,1H,4H,1D,1W)
+   • /fxint XAUUSD - <b>Forex Intraday</b> (5m,15m,1H,4H,1D)
+
+<b>4. Mode Auto SAHAM / INDEKS / KOMODITAS (Yahoo Finance):</b>
+   • /stsc AAPL - <b>Saham Scalping</b> (5m,15m,1H,1D)
+   • /stsw BBCA.JK - <b>Saham Swing</b> (1H,1D,1W)
+   • /stint ^GSPC - <b>Saham Intraday</b> (5m,15m,1H,1D)
+   <i>4H hanya untuk futures (CL=F, GC=F) yang buka ~23 jam</i>
+
+<b>5. Import Data (CSV/JSON):</b>
+   • Kirim <b>FILE</b> .csv / .json berisi OHLCV
+   • Caption: <code>EURUSD swing tz=Europe/London</code>
+   • Format: CSV, export MetaTrader (MT4/MT5), JSON array
+
+<b>6. Kirim Chart Manual:</b>
    • Kirim <b>GAMBAR</b> chart Anda
    • <b>WAJIB</b> tulis nama aset di caption
    • <b>Top-Down Analysis</b>: Kirim beberapa gambar sekaligus (Album)
@@ -265,6 +286,8 @@ Selamat datang! Saya adalah asisten trading AI Anda.
 <code>/autosc ETHUSDT</code> - Crypto Scalping (Binance)
 <code>/fxsc EURUSD</code> - Forex Scalping (Yahoo Finance)
 <code>/fxsw XAUUSD</code> - Gold Swing Trading
+<code>/stsw BBCA.JK</code> - Saham BCA Swing (IDX)
+<code>/stint IHSG</code> - Indeks IHSG Intraday
 
 <b>📊 Forex Pairs:</b>
 Major: EURUSD, GBPUSD, USDJPY, USDCHF
@@ -409,30 +432,146 @@ Commodities: XAUUSD (Gold), XAGUSD (Silver)
 	}
 
 	// === Auto Trading Command Handlers ===
+
+	// Helper: ask Gemini for a data-based analysis, then send the entry chart and the result
+	// chartData supplies the candles drawn behind the parsed levels; summaries provide the S/R bands and pivot levels
+	sendDataAnalysis := func(chat *tele.Chat, statusMsg *tele.Message, tag string, inst Instrument, prompt string, book *OrderBookSummary, summaries []CandleDataSummary, chartData func() ([]Candlestick, BinanceInterval, error)) {
+		// Build Gemini request (text only, no images!)
+		parts := []*genai.Part{genai.NewPartFromText(prompt)}
+		contents := []*genai.Content{{Parts: parts, Role: "user"}}
+		
+		// Tools (Google Search for sentiment)
+		tools := []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}
+		config := &genai.GenerateContentConfig{Tools: tools}
+		
+		// Call Gemini
+		log.Printf("🤖 [%s] Calling Gemini AI...", tag)
+		resp, err := client.Models.GenerateContent(ctx, "gemini-flash-latest", contents, config)
+		
+		// Delete status message
+		if statusMsg != nil {
+			b.Delete(statusMsg)
+		}
+		
+		if err != nil {
+			log.Printf("❌ [%s] Gemini API Error: %v", tag, err)
+			b.Send(chat, "⚠️ <b>Error analyzing</b> (Quota or API Issue). Try again later.", tele.ModeHTML)
+			return
+		}
+		
+		if resp == nil || len(resp.Candidates) == 0 {
+			log.Printf("❌ [%s] Empty response from Gemini", tag)
+			b.Send(chat, "⚠️ No response from AI.", tele.ModeHTML)
+			return
+		}
+		
+		// Extract response text
+		responseText := ""
+		for _, part := range resp.Candidates[0].Content.Parts {
+			responseText += part.Text
+		}
+		
+		// Clean HTML
+		responseText = cleanHTML(responseText)
+		log.Printf("✅ [%s] Analysis received (%d chars)", tag, len(responseText))
+		
+		// Parse levels from response
+		levels := parseLevelsFromResponse(responseText, inst.Precision)
+		if levels != nil {
+			priceFmt := inst.Precision.Verb()
+			log.Printf("📊 [%s] Parsed levels: Entry="+priceFmt+", SL="+priceFmt+", TP1="+priceFmt+", TP2="+priceFmt+", TP3="+priceFmt,
+				tag, levels.Entry, levels.SL, levels.TP1, levels.TP2, levels.TP3)
+			
+			// Candles behind the levels
+			chartCandles, chartInterval, err := chartData()
+			if err == nil && len(chartCandles) > 0 {
+				// S/R bands, pivots, the SMC structure, divergences and fibonacci of the chart timeframe
+				// (detected over its full history) and the zones proposed by the AI
+				annotations := append(SRAnnotations(DetectSupportResistance(summaries)), PivotAnnotations(summaries)...)
+				annotations = append(annotations, SummaryAnnotations(summaries, chartInterval, chartCandles[0].OpenTime)...)
+				annotations = append(annotations, ParseZonesFromResponse(responseText, inst.Precision, chartCandles[len(chartCandles)-1].Close)...)
+				
+				// Generate chart with levels
+				chartImg, err := GenerateChartWithLevels(chartCandles, inst.DisplayName, chartInterval, levels, book, annotations, inst.Precision)
+				if err == nil {
+					log.Printf("📊 [%s] Generated entry chart (%d bytes)", tag, len(chartImg))
+					
+					// Send chart first
+					photo := &tele.Photo{
+						File:    tele.FromReader(bytes.NewReader(chartImg)),
+						Caption: fmt.Sprintf("📊 %s Entry Chart\n🔵 Entry: "+priceFmt+"\n🔴 SL: "+priceFmt+"\n🟢 TP1: "+priceFmt, inst.DisplayName, levels.Entry, levels.SL, levels.TP1),
+					}
+					_, err = b.Send(chat, photo)
+					if err != nil {
+						log.Printf("⚠️ [%s] Failed to send chart: %v", tag, err)
+					} else {
+						log.Printf("✅ [%s] Entry chart sent!", tag)
+					}
+				} else {
+					log.Printf("⚠️ [%s] Failed to generate chart: %v", tag, err)
+				}
+			}
+		} else {
+			log.Printf("⚠️ [%s] Could not parse levels from response", tag)
+		}
+		
+		// Send analysis result with inline buttons
+		msg, err := b.Send(chat, responseText, &tele.SendOptions{
+			ParseMode:   tele.ModeHTML,
+			ReplyMarkup: buildMarketReplyMarkup(inst),
+		})
+		
+		if err != nil {
+			log.Printf("❌ [%s] Failed to send analysis: %v", tag, err)
+		} else {
+			log.Printf("✅ [%s] Analysis sent (MsgID: %d)", tag, msg.ID)
+		}
+	}
 	
 	// Helper function to process auto chart analysis (DATA-BASED - no images)
-	processAutoChart := func(c tele.Context, tradingMode TradingMode, analysisMode AnalysisMode) error {
-		log.Printf("📥 [AUTO-DATA] Command received: mode=%s", tradingMode)
+	// One pipeline for every market: the provider registry decides where data comes from
+	processAutoChart := func(c tele.Context, market Market, tradingMode TradingMode, analysisMode AnalysisMode) error {
+		tag := getMarketLogTag(market)
+		log.Printf("📥 [%s] Command received: mode=%s", tag, tradingMode)
 		
 		// Parse symbol from command arguments
 		args := c.Args()
 		if len(args) == 0 {
-			log.Printf("⚠️ [AUTO-DATA] No symbol provided")
-			return c.Send("⚠️ <b>Mohon masukkan simbol trading!</b>\n\nContoh: <code>/autosc BTCUSDT</code>", tele.ModeHTML)
+			log.Printf("⚠️ [%s] No symbol provided", tag)
+			return c.Send(getMarketUsageText(market), tele.ModeHTML)
+		}
+		
+		// Optional exchange selector for crypto (e.g., /autosc BTCUSDT bybit)
+		exchange := ""
+		if market == MarketCrypto && len(args) > 1 {
+			exchange = args[1]
+		}
+		
+		// Resolve symbol to a data provider, falling back to another exchange when it is not listed
+		resolveCtx, cancelResolve := context.WithTimeout(ctx, autoFetchTimeout)
+		inst, err := DefaultProviders.ResolveWithFallback(resolveCtx, market, args[0], exchange)
+		cancelResolve()
+		if err != nil {
+			log.Printf("⚠️ [%s] Invalid symbol: %v", tag, err)
+			var notFound *SymbolNotFoundError
+			if errors.As(err, &notFound) && len(notFound.Suggestions) > 0 {
+				return c.Send(fmt.Sprintf("❌ <b>Symbol %s tidak ditemukan.</b>\n\n💡 Mungkin maksud Anda: <b>%s</b>\n\n<i>%s</i>",
+					notFound.Input, strings.Join(notFound.Suggestions, ", "), getMarketSymbolHint(market)), tele.ModeHTML)
+			}
+			return c.Send(fmt.Sprintf("❌ <b>Symbol tidak valid:</b> %s\n\n<i>%s</i>", err.Error(), getMarketSymbolHint(market)), tele.ModeHTML)
 		}
 		
-		symbol := strings.ToUpper(args[0])
 		userID := c.Sender().ID
 		chat := c.Chat()
 		
-		log.Printf("📊 [AUTO-DATA] Processing symbol: %s for user: %d", symbol, userID)
+		log.Printf("📊 [%s] Processing symbol: %s (%s via %s) for user: %d", tag, inst.DisplayName, inst.Symbol, inst.Provider.Name(), userID)
 		
 		// Store mode
 		userMode.Store(userID, analysisMode)
 		
 		// Send initial status
 		modeName := getModeName(analysisMode)
-		timeframes := GetTimeframesForMode(tradingMode)
+		timeframes := GetTimeframesForInstrument(inst, tradingMode)
 		tfList := ""
 		for i, tf := range timeframes {
 			if i > 0 {
@@ -441,440 +580,251 @@ Commodities: XAUUSD (Gold), XAGUSD (Silver)
 			tfList += string(tf)
 		}
 		
-		log.Printf("⏰ [AUTO-DATA] Timeframes to fetch: %s", tfList)
+		log.Printf("⏰ [%s] Timeframes to fetch: %s", tag, tfList)
 		
 		statusMsg, sendErr := b.Send(chat, fmt.Sprintf(`⏳ <b>FETCHING DATA...</b>
 
-📊 <b>Symbol:</b> %s
+📊 <b>Symbol:</b> %s (%s)
 ⚙️ <b>Mode:</b> %s
 🕐 <b>Timeframes:</b> %s
-📈 <b>Candles:</b> 500 per timeframe
+📈 <b>Market:</b> %s (%s)
 
-<i>Mengambil data dari Binance...</i>`, symbol, modeName, tfList), tele.ModeHTML)
+<i>Mengambil data dari %s...</i>`, inst.DisplayName, inst.Symbol, modeName, tfList, strings.ToUpper(string(market)), inst.Provider.Name(), inst.Provider.Name()), tele.ModeHTML)
 		
 		if sendErr != nil {
-			log.Printf("❌ [AUTO-DATA] Failed to send status message: %v", sendErr)
+			log.Printf("❌ [%s] Failed to send status message: %v", tag, sendErr)
 		} else {
-			log.Printf("✅ [AUTO-DATA] Status message sent")
+			log.Printf("✅ [%s] Status message sent", tag)
 		}
 		
 		// Run in goroutine to not block
 		go func() {
-			log.Printf("🔄 [AUTO-DATA] Starting goroutine for %s", symbol)
+			log.Printf("🔄 [%s] Starting goroutine for %s", tag, inst.Symbol)
+			
+			// Bound all market data requests so a hung upstream can't stall this goroutine
+			fetchCtx, cancelFetch := context.WithTimeout(ctx, autoFetchTimeout)
 			
 			// Fetch multi-timeframe data (500 candles for better context)
-			log.Printf("📈 [AUTO-DATA] Fetching candlestick data...")
-			summaries, err := FetchMultiTimeframeData(symbol, tradingMode, 500)
+			log.Printf("📈 [%s] Fetching candlestick data...", tag)
+			data, err := FetchProviderMultiTimeframeData(fetchCtx, inst.Provider, inst.Symbol, timeframes, 500)
+			
+			// Binance crypto: add futures positioning and order book depth (both optional)
+			var futures *FuturesDataSummary
+			if err == nil && market == MarketCrypto && inst.Provider.Name() == (BinanceProvider{}).Name() {
+				// Stream the live price of analyzed symbols from now on
+				if subErr := DefaultBinanceStream.SubscribeMiniTicker(inst.Symbol); subErr != nil {
+					log.Printf("⚠️ [%s] Ticker stream subscribe failed: %v", tag, subErr)
+				}
+				log.Printf("📈 [%s] Fetching futures data...", tag)
+				if f, futErr := FetchFuturesData(fetchCtx, inst.Symbol); futErr != nil {
+					log.Printf("⚠️ [%s] Futures data unavailable: %v", tag, futErr)
+				} else {
+					futures = &f
+					log.Printf("✅ [%s] Futures: Funding=%.4f%%, OI 24h=%.2f%%, Positioning=%s", tag, f.FundingRate*100, f.OIChange24h, f.Positioning)
+				}
+				if book, bookErr := FetchOrderBookRange(fetchCtx, inst.Symbol, DefaultOrderBookWallRange); bookErr != nil {
+					log.Printf("⚠️ [%s] Order book unavailable: %v", tag, bookErr)
+				} else {
+					summary := AnalyzeOrderBook(book, DefaultOrderBookWallRange)
+					data.OrderBook = &summary
+					log.Printf("✅ [%s] Order book: Imbalance=%+.2f, Walls=%d bid/%d ask", tag, summary.Imbalance, len(summary.BidWalls), len(summary.AskWalls))
+				}
+			}
+			cancelFetch()
 			if err != nil {
-				log.Printf("❌ [AUTO-DATA] Error fetching data: %v", err)
+				log.Printf("❌ [%s] Error fetching data: %v", tag, err)
 				if statusMsg != nil {
 					b.Delete(statusMsg)
 				}
-				b.Send(chat, fmt.Sprintf("❌ <b>Error fetching data:</b> %s\n\n<i>Pastikan symbol benar (contoh: BTCUSDT) dan koneksi internet stabil.</i>", err.Error()), tele.ModeHTML)
+				b.Send(chat, fmt.Sprintf("❌ <b>Error fetching data:</b> %s\n\n<i>Pastikan symbol benar dan koneksi internet stabil.\n%s</i>", err.Error(), getMarketSymbolHint(market)), tele.ModeHTML)
 				return
 			}
-			log.Printf("✅ [AUTO-DATA] Fetched data for %d timeframes", len(summaries))
+			log.Printf("✅ [%s] Fetched data for %d/%d timeframes", tag, len(data.Summaries), len(timeframes))
 			
 			// Log summary for each timeframe
-			for _, s := range summaries {
-				log.Printf("📋 [AUTO-DATA] %s: Trend=%s, RSI=%.1f, Change=%.2f%%", 
-					GetTimeframeName(s.Interval), s.Trend, s.RSI, s.PriceChange)
+			for _, s := range data.Summaries {
+				log.Printf("📋 [%s] %s: Trend=%s, RSI=%.1f, Change=%.2f%%", 
+					tag, GetTimeframeName(s.Interval), s.Trend, s.RSI, s.PriceChange)
+			}
+			missingList := ""
+			for i, f := range data.Failed {
+				log.Printf("⚠️ [%s] %s unavailable: %v", tag, GetTimeframeName(f.Interval), f.Err)
+				if i > 0 {
+					missingList += ", "
+				}
+				missingList += string(f.Interval)
 			}
 			
 			// Update status
 			if statusMsg != nil {
+				missingLine := ""
+				if missingList != "" {
+					missingLine = fmt.Sprintf("\n⚠️ <b>Missing:</b> %s", missingList)
+				}
 				b.Edit(statusMsg, fmt.Sprintf(`✅ <b>DATA FETCHED!</b>
 
 📊 <b>Symbol:</b> %s
-📈 <b>Timeframes:</b> %d
-🤖 <b>Status:</b> Analyzing with AI...`, symbol, len(summaries)), tele.ModeHTML)
+📈 <b>Timeframes:</b> %d/%d%s
+🤖 <b>Status:</b> Analyzing with AI...`, inst.DisplayName, len(data.Summaries), len(timeframes), missingLine), tele.ModeHTML)
 			}
 			
 			// Format data for AI
-			dataContext := FormatDataForAI(symbol, summaries, tradingMode)
-			log.Printf("📝 [AUTO-DATA] Data formatted for AI (%d bytes)", len(dataContext))
-			
-			// Generate specialized prompt for data analysis
-			prompt := GenerateDataAnalysisPrompt(tradingMode, symbol, dataContext)
-			
-			// Build Gemini request (text only, no images!)
-			parts := []*genai.Part{genai.NewPartFromText(prompt)}
-			contents := []*genai.Content{{Parts: parts, Role: "user"}}
-			
-			// Tools (Google Search for sentiment)
-			tools := []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}
-			config := &genai.GenerateContentConfig{Tools: tools}
-			
-			// Call Gemini
-			log.Printf("🤖 [AUTO-DATA] Calling Gemini AI...")
-			resp, err := client.Models.GenerateContent(ctx, "gemini-flash-latest", contents, config)
-			
-			// Delete status message
-			if statusMsg != nil {
-				b.Delete(statusMsg)
-			}
-			
-			if err != nil {
-				log.Printf("❌ [AUTO-DATA] Gemini API Error: %v", err)
-				b.Send(chat, "⚠️ <b>Error analyzing</b> (Quota or API Issue). Try again later.", tele.ModeHTML)
-				return
-			}
-			
-			if resp == nil || len(resp.Candidates) == 0 {
-				log.Printf("❌ [AUTO-DATA] Empty response from Gemini")
-				b.Send(chat, "⚠️ No response from AI.", tele.ModeHTML)
-				return
-			}
-			
-			// Extract response text
-			responseText := ""
-			for _, part := range resp.Candidates[0].Content.Parts {
-				responseText += part.Text
+			dataContext := FormatMarketDataForAI(inst, data, tradingMode)
+			if futures != nil {
+				dataContext += FormatFuturesDataForAI(*futures, inst.Precision)
 			}
+			log.Printf("📝 [%s] Data formatted for AI (%d bytes)", tag, len(dataContext))
 			
-			// Clean HTML
-			responseText = cleanHTML(responseText)
-			log.Printf("✅ [AUTO-DATA] Analysis received (%d chars)", len(responseText))
+			// Generate specialized prompt for data analysis
+			prompt := GenerateMarketAnalysisPrompt(inst, tradingMode, dataContext)
 			
-			// Parse levels from response
-			levels := parseLevelsFromResponse(responseText)
-			if levels != nil {
-				log.Printf("📊 [AUTO-DATA] Parsed levels: Entry=%.2f, SL=%.2f, TP1=%.2f, TP2=%.2f, TP3=%.2f",
-					levels.Entry, levels.SL, levels.TP1, levels.TP2, levels.TP3)
-				
-				// Get best timeframe for chart (1H for scalping, 4H for swing/intraday)
-				chartInterval := Interval1h
-				if tradingMode == TradingModeSwing {
-					chartInterval = Interval4h
-				}
-				
-				// Fetch candles for chart
-				chartCandles, err := FetchCandlesticks(symbol, chartInterval, 100)
-				if err == nil {
-					// Generate chart with levels
-					chartImg, err := GenerateChartWithLevels(chartCandles, symbol, chartInterval, levels)
-					if err == nil {
-						log.Printf("📊 [AUTO-DATA] Generated entry chart (%d bytes)", len(chartImg))
-						
-						// Send chart first
-						photo := &tele.Photo{
-							File:    tele.FromReader(bytes.NewReader(chartImg)),
-							Caption: fmt.Sprintf("📊 %s Entry Chart\n🔵 Entry: %.2f\n🔴 SL: %.2f\n🟢 TP1: %.2f", symbol, levels.Entry, levels.SL, levels.TP1),
-						}
-						_, err = b.Send(chat, photo)
-						if err != nil {
-							log.Printf("⚠️ [AUTO-DATA] Failed to send chart: %v", err)
-						} else {
-							log.Printf("✅ [AUTO-DATA] Entry chart sent!")
-						}
-					} else {
-						log.Printf("⚠️ [AUTO-DATA] Failed to generate chart: %v", err)
-					}
-				}
-			} else {
-				log.Printf("⚠️ [AUTO-DATA] Could not parse levels from response")
-			}
-			
-			// Send analysis result with inline buttons
-			msg, err := b.Send(chat, responseText, &tele.SendOptions{
-				ParseMode: tele.ModeHTML,
-				ReplyMarkup: &tele.ReplyMarkup{
-					InlineKeyboard: [][]tele.InlineButton{
-						{
-							{
-								Text: "📈 TradingView",
-								URL:  fmt.Sprintf("https://www.tradingview.com/chart/?symbol=BINANCE:%s", symbol),
-							},
-							{
-								Text: "📰 News",
-								URL:  fmt.Sprintf("https://www.google.com/search?q=%s+crypto+news", symbol),
-							},
-						},
-						{
-							{
-								Text:   "⚠️ Disclaimer",
-								Unique: "disclaimer_btn",
-							},
-						},
-					},
-				},
-			})
-			
-			if err != nil {
-				log.Printf("❌ [AUTO-DATA] Failed to send analysis: %v", err)
-			} else {
-				log.Printf("✅ [AUTO-DATA] Analysis sent (MsgID: %d)", msg.ID)
+			// Chart candles come from the provider at the mode's chart interval
+			chartData := func() ([]Candlestick, BinanceInterval, error) {
+				chartInterval := GetChartIntervalForMarket(market, tradingMode)
+				chartCtx, cancelChart := context.WithTimeout(ctx, autoFetchTimeout)
+				defer cancelChart()
+				candles, err := FetchCandlesWithResample(chartCtx, inst.Provider, inst.Symbol, chartInterval, 100, 0)
+				return candles, chartInterval, err
 			}
+			sendDataAnalysis(chat, statusMsg, tag, inst, prompt, data.OrderBook, data.Summaries, chartData)
 		}()
 		
-		log.Printf("⏳ [AUTO-DATA] Goroutine started, returning immediately")
+		log.Printf("⏳ [%s] Goroutine started, returning immediately", tag)
 		return nil
 	}
 	
 	// /autosc - Auto Scalping
 	b.Handle("/autosc", func(c tele.Context) error {
 		log.Printf("🔥 [HANDLER] /autosc triggered by user %d", c.Sender().ID)
-		return processAutoChart(c, TradingModeScalping, ModeAutoScalping)
+		return processAutoChart(c, MarketCrypto, TradingModeScalping, ModeAutoScalping)
 	})
 	
 	// /autosw - Auto Swing
 	b.Handle("/autosw", func(c tele.Context) error {
 		log.Printf("🔥 [HANDLER] /autosw triggered by user %d", c.Sender().ID)
-		return processAutoChart(c, TradingModeSwing, ModeAutoSwing)
+		return processAutoChart(c, MarketCrypto, TradingModeSwing, ModeAutoSwing)
 	})
 	
 	// /autoint - Auto Intraday
 	b.Handle("/autoint", func(c tele.Context) error {
 		log.Printf("🔥 [HANDLER] /autoint triggered by user %d", c.Sender().ID)
-		return processAutoChart(c, TradingModeIntraday, ModeAutoIntraday)
+		return processAutoChart(c, MarketCrypto, TradingModeIntraday, ModeAutoIntraday)
 	})
 
 	// === FOREX Auto Trading Command Handlers ===
 	
-	// Helper function to process auto forex chart analysis (DATA-BASED - Yahoo Finance)
-	processAutoForexChart := func(c tele.Context, tradingMode TradingMode, analysisMode AnalysisMode) error {
-		log.Printf("📥 [FOREX-AUTO] Command received: mode=%s", tradingMode)
-		
-		// Parse symbol from command arguments
-		args := c.Args()
-		if len(args) == 0 {
-			log.Printf("⚠️ [FOREX-AUTO] No symbol provided")
-			return c.Send(`⚠️ <b>Mohon masukkan simbol forex!</b>
+	// /fxsc - Forex Scalping
+	b.Handle("/fxsc", func(c tele.Context) error {
+		log.Printf("🔥 [HANDLER] /fxsc triggered by user %d", c.Sender().ID)
+		return processAutoChart(c, MarketForex, TradingModeScalping, ModeAutoScalping)
+	})
+	
+	// /fxsw - Forex Swing
+	b.Handle("/fxsw", func(c tele.Context) error {
+		log.Printf("🔥 [HANDLER] /fxsw triggered by user %d", c.Sender().ID)
+		return processAutoChart(c, MarketForex, TradingModeSwing, ModeAutoSwing)
+	})
+	
+	// /fxint - Forex Intraday
+	b.Handle("/fxint", func(c tele.Context) error {
+		log.Printf("🔥 [HANDLER] /fxint triggered by user %d", c.Sender().ID)
+		return processAutoChart(c, MarketForex, TradingModeIntraday, ModeAutoIntraday)
+	})
 
-<b>Contoh:</b>
-• <code>/fxsc EURUSD</code> - Scalping EUR/USD
-• <code>/fxsw GBPJPY</code> - Swing GBP/JPY
-• <code>/fxint XAUUSD</code> - Intraday Gold
+	// /stsc - Stocks Scalping
+	b.Handle("/stsc", func(c tele.Context) error {
+		log.Printf("🔥 [HANDLER] /stsc triggered by user %d", c.Sender().ID)
+		return processAutoChart(c, MarketStocks, TradingModeScalping, ModeAutoScalping)
+	})
 
-<b>Major Pairs:</b> EURUSD, GBPUSD, USDJPY, USDCHF, AUDUSD, USDCAD, NZDUSD
-<b>Cross Pairs:</b> EURGBP, EURJPY, GBPJPY, EURAUD, dll
-<b>Commodities:</b> XAUUSD (Gold), XAGUSD (Silver)`, tele.ModeHTML)
+	// /stsw - Stocks Swing
+	b.Handle("/stsw", func(c tele.Context) error {
+		log.Printf("🔥 [HANDLER] /stsw triggered by user %d", c.Sender().ID)
+		return processAutoChart(c, MarketStocks, TradingModeSwing, ModeAutoSwing)
+	})
+
+	// /stint - Stocks Intraday
+	b.Handle("/stint", func(c tele.Context) error {
+		log.Printf("🔥 [HANDLER] /stint triggered by user %d", c.Sender().ID)
+		return processAutoChart(c, MarketStocks, TradingModeIntraday, ModeAutoIntraday)
+	})
+
+	// === Document Handler (Candle Import) ===
+	// Analyze an uploaded CSV/MetaTrader/JSON candle file like /autosc does for live data
+	b.Handle(tele.OnDocument, func(c tele.Context) error {
+		doc := c.Message().Document
+		tag := getMarketLogTag(MarketImported)
+		log.Printf("📥 [%s] Document received: %s (%d bytes) from user %d", tag, doc.FileName, doc.FileSize, c.Sender().ID)
+
+		switch strings.ToLower(filepath.Ext(doc.FileName)) {
+		case ".csv", ".txt", ".json":
+		default:
+			return c.Send(getMarketUsageText(MarketImported), tele.ModeHTML)
 		}
-		
-		// Normalize forex symbol
-		yahooSymbol, displayName, err := NormalizeForexSymbol(args[0])
+		if doc.FileSize > maxImportFileSize {
+			return c.Send(fmt.Sprintf("❌ <b>File terlalu besar.</b> Maksimal %d MB.", maxImportFileSize>>20), tele.ModeHTML)
+		}
+
+		spec, err := ParseImportSpec(c.Message().Caption, doc.FileName)
 		if err != nil {
-			log.Printf("⚠️ [FOREX-AUTO] Invalid symbol: %v", err)
-			return c.Send(fmt.Sprintf("❌ <b>Symbol tidak valid:</b> %s\n\n<i>Gunakan format seperti: EURUSD, EUR/USD, GBPJPY</i>", err.Error()), tele.ModeHTML)
+			return c.Send(fmt.Sprintf("❌ <b>Caption tidak valid:</b> %s\n\n%s", err.Error(), getMarketUsageText(MarketImported)), tele.ModeHTML)
 		}
-		
-		userID := c.Sender().ID
-		chat := c.Chat()
-		
-		log.Printf("📊 [FOREX-AUTO] Processing symbol: %s (%s) for user: %d", displayName, yahooSymbol, userID)
-		
-		// Store mode
-		userMode.Store(userID, analysisMode)
-		
-		// Send initial status
-		modeName := getModeName(analysisMode)
-		timeframes := GetForexTimeframesForMode(tradingMode)
-		tfList := ""
-		for i, tf := range timeframes {
-			if i > 0 {
-				tfList += ", "
-			}
-			tfList += string(tf)
+
+		rc, err := b.File(&doc.File)
+		if err != nil {
+			return err
 		}
-		
-		log.Printf("⏰ [FOREX-AUTO] Timeframes to fetch: %s", tfList)
-		
-		statusMsg, sendErr := b.Send(chat, fmt.Sprintf(`⏳ <b>FETCHING FOREX DATA...</b>
+		result, err := ImportCandles(rc, spec.Options)
+		rc.Close()
+		if err != nil {
+			log.Printf("⚠️ [%s] Import failed: %v", tag, err)
+			return c.Send(fmt.Sprintf("❌ <b>Gagal membaca file:</b> %s\n\n<i>%s</i>", err.Error(), getMarketSymbolHint(MarketImported)), tele.ModeHTML)
+		}
+		log.Printf("✅ [%s] Imported %d %s candles (%s, %d rows skipped)", tag, len(result.Candles), result.Interval, result.Format, result.Skipped)
+
+		inst := Instrument{
+			Symbol:      spec.Symbol,
+			DisplayName: spec.Symbol,
+			Market:      MarketImported,
+			Precision:   result.Precision,
+		}
+		candles := result.Candles
+		chat := c.Chat()
+
+		first, last := candles[0], candles[len(candles)-1]
+		statusMsg, sendErr := b.Send(chat, fmt.Sprintf(`✅ <b>FILE IMPORTED!</b>
 
-💱 <b>Pair:</b> %s
 📊 <b>Symbol:</b> %s
+🕐 <b>Timeframe:</b> %s (%d candles)
+📅 <b>Periode:</b> %s - %s
 ⚙️ <b>Mode:</b> %s
-🕐 <b>Timeframes:</b> %s
-📈 <b>Market:</b> FOREX (Yahoo Finance)
-
-<i>Mengambil data dari Yahoo Finance...</i>`, displayName, yahooSymbol, modeName, tfList), tele.ModeHTML)
-		
+🤖 <b>Status:</b> Analyzing with AI...`, inst.DisplayName, result.Interval, len(candles),
+			first.OpenTime.Format("2006-01-02 15:04"), last.OpenTime.Format("2006-01-02 15:04"), strings.ToUpper(string(spec.Mode))), tele.ModeHTML)
 		if sendErr != nil {
-			log.Printf("❌ [FOREX-AUTO] Failed to send status message: %v", sendErr)
-		} else {
-			log.Printf("✅ [FOREX-AUTO] Status message sent")
+			log.Printf("❌ [%s] Failed to send status message: %v", tag, sendErr)
 		}
-		
-		// Run in goroutine to not block
-		go func() {
-			log.Printf("🔄 [FOREX-AUTO] Starting goroutine for %s", yahooSymbol)
-			
-			// Fetch multi-timeframe data (200 candles for context)
-			log.Printf("📈 [FOREX-AUTO] Fetching candlestick data...")
-			summaries, err := FetchForexMultiTimeframeData(yahooSymbol, tradingMode, 500)
-			if err != nil {
-				log.Printf("❌ [FOREX-AUTO] Error fetching data: %v", err)
-				if statusMsg != nil {
-					b.Delete(statusMsg)
-				}
-				b.Send(chat, fmt.Sprintf(`❌ <b>Error fetching forex data:</b> %s
 
-<i>Pastikan symbol benar dan koneksi internet stabil.
-Contoh symbol: EURUSD, GBPJPY, XAUUSD</i>`, err.Error()), tele.ModeHTML)
-				return
-			}
-			log.Printf("✅ [FOREX-AUTO] Fetched data for %d timeframes", len(summaries))
-			
-			// Log summary for each timeframe
-			for _, s := range summaries {
-				log.Printf("📋 [FOREX-AUTO] %s: Trend=%s, RSI=%.1f, Change=%.2f%%", 
-					GetTimeframeName(s.Interval), s.Trend, s.RSI, s.PriceChange)
-			}
-			
-			// Update status
-			if statusMsg != nil {
-				b.Edit(statusMsg, fmt.Sprintf(`✅ <b>DATA FETCHED!</b>
-
-💱 <b>Pair:</b> %s
-📈 <b>Timeframes:</b> %d
-🤖 <b>Status:</b> Analyzing with AI...`, displayName, len(summaries)), tele.ModeHTML)
-			}
-			
-			// Format data for AI
-			dataContext := FormatForexDataForAI(yahooSymbol, displayName, summaries, tradingMode)
-			log.Printf("📝 [FOREX-AUTO] Data formatted for AI (%d bytes)", len(dataContext))
-			
-			// Generate specialized forex prompt
-			prompt := GenerateForexAnalysisPrompt(tradingMode, yahooSymbol, displayName, dataContext)
-			
-			// Build Gemini request (text only, no images!)
-			parts := []*genai.Part{genai.NewPartFromText(prompt)}
-			contents := []*genai.Content{{Parts: parts, Role: "user"}}
-			
-			// Tools (Google Search for sentiment and economic calendar)
-			tools := []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}
-			config := &genai.GenerateContentConfig{Tools: tools}
-			
-			// Call Gemini
-			log.Printf("🤖 [FOREX-AUTO] Calling Gemini AI...")
-			resp, err := client.Models.GenerateContent(ctx, "gemini-flash-latest", contents, config)
-			
-			// Delete status message
-			if statusMsg != nil {
-				b.Delete(statusMsg)
-			}
-			
-			if err != nil {
-				log.Printf("❌ [FOREX-AUTO] Gemini API Error: %v", err)
-				b.Send(chat, "⚠️ <b>Error analyzing</b> (Quota or API Issue). Try again later.", tele.ModeHTML)
-				return
-			}
-			
-			if resp == nil || len(resp.Candidates) == 0 {
-				log.Printf("❌ [FOREX-AUTO] Empty response from Gemini")
-				b.Send(chat, "⚠️ No response from AI.", tele.ModeHTML)
-				return
-			}
-			
-			// Extract response text
-			responseText := ""
-			for _, part := range resp.Candidates[0].Content.Parts {
-				responseText += part.Text
-			}
-			
-			// Clean HTML
-			responseText = cleanHTML(responseText)
-			log.Printf("✅ [FOREX-AUTO] Analysis received (%d chars)", len(responseText))
-			
-			// Parse levels from response
-			levels := parseLevelsFromResponse(responseText)
-			if levels != nil {
-				log.Printf("📊 [FOREX-AUTO] Parsed levels: Entry=%.5f, SL=%.5f, TP1=%.5f, TP2=%.5f, TP3=%.5f",
-					levels.Entry, levels.SL, levels.TP1, levels.TP2, levels.TP3)
-				
-				// Get best timeframe for chart (1H for scalping/intraday, 1D for swing)
-				chartInterval := YahooInterval1h
-				binanceChartInterval := Interval1h
-				if tradingMode == TradingModeSwing {
-					chartInterval = YahooInterval1d
-					binanceChartInterval = Interval1d
-				}
-				
-				// Fetch candles for chart
-				chartCandles, err := FetchYahooCandlesticks(yahooSymbol, chartInterval, 100)
-				if err == nil && len(chartCandles) > 0 {
-					// Generate chart with levels
-					chartImg, err := GenerateChartWithLevels(chartCandles, displayName, binanceChartInterval, levels)
-					if err == nil {
-						log.Printf("📊 [FOREX-AUTO] Generated entry chart (%d bytes)", len(chartImg))
-						
-						// Send chart first
-						photo := &tele.Photo{
-							File:    tele.FromReader(bytes.NewReader(chartImg)),
-							Caption: fmt.Sprintf("📊 %s Entry Chart\n🔵 Entry: %.5f\n🔴 SL: %.5f\n🟢 TP1: %.5f", displayName, levels.Entry, levels.SL, levels.TP1),
-						}
-						_, err = b.Send(chat, photo)
-						if err != nil {
-							log.Printf("⚠️ [FOREX-AUTO] Failed to send chart: %v", err)
-						} else {
-							log.Printf("✅ [FOREX-AUTO] Entry chart sent!")
-						}
-					} else {
-						log.Printf("⚠️ [FOREX-AUTO] Failed to generate chart: %v", err)
-					}
+		go func() {
+			// Imported data is historical: judge staleness against its own last bar
+			summary := AnalyzeCandlestickData(candles, result.Interval)
+			summary.Pivots = ComputePivotPoints(candles, result.Interval, ImportCalendar(candles), time.Now())
+			summary.Quality = AssessDataQuality(candles, result.Interval, ImportCalendar(candles), last.CloseTime)
+			data := MultiTimeframeResult{Summaries: []CandleDataSummary{summary}}
+
+			dataContext := FormatMarketDataForAI(inst, data, spec.Mode)
+			dataContext += fmt.Sprintf("Data Source: file upload %s (%s, %d rows skipped). Data ini historis dari user, bukan harga live.\n", doc.FileName, result.Format, result.Skipped)
+			log.Printf("📝 [%s] Data formatted for AI (%d bytes)", tag, len(dataContext))
+
+			prompt := GenerateMarketAnalysisPrompt(inst, spec.Mode, dataContext)
+			chartData := func() ([]Candlestick, BinanceInterval, error) {
+				if len(candles) > 100 {
+					return candles[len(candles)-100:], result.Interval, nil
 				}
-			} else {
-				log.Printf("⚠️ [FOREX-AUTO] Could not parse levels from response")
-			}
-			
-			// Send analysis result with inline buttons
-			msg, err := b.Send(chat, responseText, &tele.SendOptions{
-				ParseMode: tele.ModeHTML,
-				ReplyMarkup: &tele.ReplyMarkup{
-					InlineKeyboard: [][]tele.InlineButton{
-						{
-							{
-								Text: "📈 TradingView",
-								URL:  fmt.Sprintf("https://www.tradingview.com/chart/?symbol=FX:%s", strings.ReplaceAll(displayName, "/", "")),
-							},
-							{
-								Text: "📰 Forex News",
-								URL:  fmt.Sprintf("https://www.google.com/search?q=%s+forex+news", strings.ReplaceAll(displayName, "/", "")),
-							},
-						},
-						{
-							{
-								Text: "📅 Economic Calendar",
-								URL:  "https://www.forexfactory.com/calendar",
-							},
-						},
-						{
-							{
-								Text:   "⚠️ Disclaimer",
-								Unique: "disclaimer_btn",
-							},
-						},
-					},
-				},
-			})
-			
-			if err != nil {
-				log.Printf("❌ [FOREX-AUTO] Failed to send analysis: %v", err)
-			} else {
-				log.Printf("✅ [FOREX-AUTO] Analysis sent (MsgID: %d)", msg.ID)
+				return candles, result.Interval, nil
 			}
+			sendDataAnalysis(chat, statusMsg, tag, inst, prompt, nil, data.Summaries, chartData)
 		}()
-		
-		log.Printf("⏳ [FOREX-AUTO] Goroutine started, returning immediately")
 		return nil
-	}
-	
-	// /fxsc - Forex Scalping
-	b.Handle("/fxsc", func(c tele.Context) error {
-		log.Printf("🔥 [HANDLER] /fxsc triggered by user %d", c.Sender().ID)
-		return processAutoForexChart(c, TradingModeScalping, ModeAutoScalping)
-	})
-	
-	// /fxsw - Forex Swing
-	b.Handle("/fxsw", func(c tele.Context) error {
-		log.Printf("🔥 [HANDLER] /fxsw triggered by user %d", c.Sender().ID)
-		return processAutoForexChart(c, TradingModeSwing, ModeAutoSwing)
-	})
-	
-	// /fxint - Forex Intraday
-	b.Handle("/fxint", func(c tele.Context) error {
-		log.Printf("🔥 [HANDLER] /fxint triggered by user %d", c.Sender().ID)
-		return processAutoForexChart(c, TradingModeIntraday, ModeAutoIntraday)
 	})
 
 	// === Helper: Interactive Callbacks ===
@@ -988,11 +938,172 @@ Contoh symbol: EURUSD, GBPJPY, XAUUSD</i>`, err.Error()), tele.ModeHTML)
 	
 	b.Handle(tele.OnPhoto, handlePhoto)
 
-	log.Println("📋 [STARTUP] Registered handlers: /analyst, /scalping, /autosc, /autosw, /autoint, /fxsc, /fxsw, /fxint, /help, /start")
+	log.Println("📋 [STARTUP] Registered handlers: /analyst, /scalping, /autosc, /autosw, /autoint, /fxsc, /fxsw, /fxint, /stsc, /stsw, /stint, document import, /help, /start")
 	fmt.Println("🚀 Antigravity Bot (Multi-Mode) Started...")
 	b.Start()
 }
 
+// === Market Presentation Helpers ===
+
+// getMarketLogTag returns the log prefix for a market
+func getMarketLogTag(market Market) string {
+	switch market {
+	case MarketForex:
+		return "FOREX-AUTO"
+	case MarketStocks:
+		return "STOCK-AUTO"
+	case MarketImported:
+		return "IMPORT"
+	default:
+		return "AUTO-DATA"
+	}
+}
+
+// getMarketUsageText returns the usage message shown when no symbol is given
+func getMarketUsageText(market Market) string {
+	switch market {
+	case MarketForex:
+		return `⚠️ <b>Mohon masukkan simbol forex!</b>
+
+<b>Contoh:</b>
+• <code>/fxsc EURUSD</code> - Scalping EUR/USD
+• <code>/fxsw GBPJPY</code> - Swing GBP/JPY
+• <code>/fxint XAUUSD</code> - Intraday Gold
+
+<b>Major Pairs:</b> EURUSD, GBPUSD, USDJPY, USDCHF, AUDUSD, USDCAD, NZDUSD
+<b>Cross Pairs:</b> EURGBP, EURJPY, GBPJPY, EURAUD, dll
+<b>Commodities:</b> XAUUSD (Gold), XAGUSD (Silver)`
+	case MarketStocks:
+		return `⚠️ <b>Mohon masukkan ticker saham/indeks!</b>
+
+<b>Contoh:</b>
+• <code>/stsc AAPL</code> - Scalping Apple
+• <code>/stsw BBCA.JK</code> - Swing BCA (IDX)
+• <code>/stint ^GSPC</code> - Intraday S&amp;P 500
+
+<b>US Stocks:</b> AAPL, MSFT, NVDA, TSLA
+<b>IDX:</b> tambahkan .JK (BBCA.JK, TLKM.JK, BBRI.JK)
+<b>Indeks:</b> ^GSPC (SPX), ^IXIC (NASDAQ), ^JKSE (IHSG)
+<b>Futures:</b> CL=F (WTI), GC=F (GOLD), NG=F (NATGAS)`
+	case MarketImported:
+		return `⚠️ <b>Kirim file candle .csv atau .json!</b>
+
+<b>Caption (opsional):</b> <code>SYMBOL mode tz=Zona cols=kolom format=csv|mt|json</code>
+
+<b>Contoh:</b>
+• <code>EURUSD swing</code> - Export MetaTrader, mode swing
+• <code>BTCUSDT scalping tz=Asia/Jakarta</code> - Waktu lokal WIB
+• <code>AAPL cols=date,skip,open,high,low,close,volume</code>
+
+<b>Format:</b> CSV dengan header (time/open/high/low/close/volume), export MT4/MT5, atau JSON array
+<b>Timeframe:</b> dideteksi otomatis (1m - 1W), maksimal 5 MB`
+	default:
+		return fmt.Sprintf("⚠️ <b>Mohon masukkan simbol trading!</b>\n\nContoh: <code>/autosc BTCUSDT</code>\nPilih exchange: <code>/autosc BTCUSDT bybit</code>\n\n<b>Exchange:</b> %s\n<i>Jika symbol tidak ada di exchange pertama, otomatis dicoba di exchange berikutnya.</i>", strings.Join(CryptoExchangeNames(), ", "))
+	}
+}
+
+// getMarketSymbolHint returns a short example of valid symbols for a market
+func getMarketSymbolHint(market Market) string {
+	switch market {
+	case MarketForex:
+		return "Contoh symbol: EURUSD, EUR/USD, GBPJPY, XAUUSD"
+	case MarketStocks:
+		return "Contoh ticker: AAPL, BBCA.JK, ^GSPC, IHSG, CL=F"
+	case MarketImported:
+		return "Format: time,open,high,low,close,volume (CSV), export MetaTrader, atau JSON array"
+	default:
+		return "Contoh symbol: BTC, BTCUSDT, ETH/BTC"
+	}
+}
+
+// buildMarketReplyMarkup builds the inline buttons attached to an analysis result
+func buildMarketReplyMarkup(inst Instrument) *tele.ReplyMarkup {
+	disclaimerRow := []tele.InlineButton{
+		{
+			Text:   "⚠️ Disclaimer",
+			Unique: "disclaimer_btn",
+		},
+	}
+
+	switch inst.Market {
+	case MarketForex:
+		pair := strings.ReplaceAll(inst.DisplayName, "/", "")
+		return &tele.ReplyMarkup{
+			InlineKeyboard: [][]tele.InlineButton{
+				{
+					{
+						Text: "📈 TradingView",
+						URL:  fmt.Sprintf("https://www.tradingview.com/chart/?symbol=FX:%s", pair),
+					},
+					{
+						Text: "📰 Forex News",
+						URL:  fmt.Sprintf("https://www.google.com/search?q=%s+forex+news", pair),
+					},
+				},
+				{
+					{
+						Text: "📅 Economic Calendar",
+						URL:  "https://www.forexfactory.com/calendar",
+					},
+				},
+				disclaimerRow,
+			},
+		}
+	case MarketStocks:
+		return &tele.ReplyMarkup{
+			InlineKeyboard: [][]tele.InlineButton{
+				{
+					{
+						Text: "📈 TradingView",
+						URL:  fmt.Sprintf("https://www.tradingview.com/chart/?symbol=%s", url.QueryEscape(TradingViewSymbol(inst.Symbol))),
+					},
+					{
+						Text: "📰 News",
+						URL:  fmt.Sprintf("https://www.google.com/search?q=%s+stock+news", url.QueryEscape(inst.DisplayName)),
+					},
+				},
+				{
+					{
+						Text: "💹 Yahoo Finance",
+						URL:  fmt.Sprintf("https://finance.yahoo.com/quote/%s", url.PathEscape(inst.Symbol)),
+					},
+				},
+				disclaimerRow,
+			},
+		}
+	case MarketImported:
+		return &tele.ReplyMarkup{
+			InlineKeyboard: [][]tele.InlineButton{
+				{
+					{
+						Text: "📈 TradingView",
+						URL:  fmt.Sprintf("https://www.tradingview.com/chart/?symbol=%s", url.QueryEscape(inst.Symbol)),
+					},
+				},
+				disclaimerRow,
+			},
+		}
+	default:
+		// TradingView prefixes match the exchange names (BINANCE:, BYBIT:, OKX:, ...)
+		pair := strings.ReplaceAll(inst.DisplayName, "/", "")
+		return &tele.ReplyMarkup{
+			InlineKeyboard: [][]tele.InlineButton{
+				{
+					{
+						Text: "📈 TradingView",
+						URL:  fmt.Sprintf("https://www.tradingview.com/chart/?symbol=%s:%s", strings.ToUpper(inst.Provider.Name()), pair),
+					},
+					{
+						Text: "📰 News",
+						URL:  fmt.Sprintf("https://www.google.com/search?q=%s+crypto+news", pair),
+					},
+				},
+				disclaimerRow,
+			},
+		}
+	}
+}
+
 func cleanHTML(text string) string {
 	// 1. Convert Markdown Bold (**text**) to HTML Bold (<b>text</b>)
 	countBold := strings.Count(text, "**")
@@ -1022,33 +1133,36 @@ func cleanHTML(text string) string {
 }
 
 // parseLevelsFromResponse extracts Entry, SL, TP levels from AI response
-func parseLevelsFromResponse(text string) *TradeLevels {
+// Levels are snapped to the instrument tick size
+func parseLevelsFromResponse(text string, prec InstrumentPrecision) *TradeLevels {
 	levels := &TradeLevels{}
 	
 	// Regex patterns to extract price levels
-	// Looking for patterns like "ENTRY:   98500.00" or "+ ENTRY: 98500"
-	entryRe := regexp.MustCompile(`(?i)ENTRY[:\s]+([0-9]+\.?[0-9]*)`)
-	slRe := regexp.MustCompile(`(?i)(?:SL|STOPLOSS)[:\s]+([0-9]+\.?[0-9]*)`)
-	tp1Re := regexp.MustCompile(`(?i)TP\s*1[:\s]+([0-9]+\.?[0-9]*)`)
-	tp2Re := regexp.MustCompile(`(?i)TP\s*2[:\s]+([0-9]+\.?[0-9]*)`)
-	tp3Re := regexp.MustCompile(`(?i)TP\s*3[:\s]+([0-9]+\.?[0-9]*)`)
+	// Looking for patterns like "ENTRY:   98500.00", "+ ENTRY: 98,500" or "ENTRY: 151.235"
+	entryRe := regexp.MustCompile(`(?i)ENTRY[:\s]+([0-9][0-9,]*\.?[0-9]*)`)
+	slRe := regexp.MustCompile(`(?i)(?:SL|STOPLOSS)[:\s]+([0-9][0-9,]*\.?[0-9]*)`)
+	tp1Re := regexp.MustCompile(`(?i)TP\s*1[:\s]+([0-9][0-9,]*\.?[0-9]*)`)
+	tp2Re := regexp.MustCompile(`(?i)TP\s*2[:\s]+([0-9][0-9,]*\.?[0-9]*)`)
+	tp3Re := regexp.MustCompile(`(?i)TP\s*3[:\s]+([0-9][0-9,]*\.?[0-9]*)`)
 	
-	if match := entryRe.FindStringSubmatch(text); len(match) > 1 {
-		levels.Entry, _ = strconv.ParseFloat(match[1], 64)
-	}
-	if match := slRe.FindStringSubmatch(text); len(match) > 1 {
-		levels.SL, _ = strconv.ParseFloat(match[1], 64)
-	}
-	if match := tp1Re.FindStringSubmatch(text); len(match) > 1 {
-		levels.TP1, _ = strconv.ParseFloat(match[1], 64)
-	}
-	if match := tp2Re.FindStringSubmatch(text); len(match) > 1 {
-		levels.TP2, _ = strconv.ParseFloat(match[1], 64)
-	}
-	if match := tp3Re.FindStringSubmatch(text); len(match) > 1 {
-		levels.TP3, _ = strconv.ParseFloat(match[1], 64)
+	parseLevel := func(re *regexp.Regexp) float64 {
+		match := re.FindStringSubmatch(text)
+		if len(match) < 2 {
+			return 0
+		}
+		value, err := parsePriceNumber(strings.TrimRight(match[1], ","))
+		if err != nil {
+			return 0
+		}
+		return prec.Round(value)
 	}
 	
+	levels.Entry = parseLevel(entryRe)
+	levels.SL = parseLevel(slRe)
+	levels.TP1 = parseLevel(tp1Re)
+	levels.TP2 = parseLevel(tp2Re)
+	levels.TP3 = parseLevel(tp3Re)
+	
 	// Check if we got at least entry and SL
 	if levels.Entry > 0 && levels.SL > 0 {
 		return levels
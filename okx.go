@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// OKXBaseURL is the OKX v5 public API
+const OKXBaseURL = "https://www.okx.com"
+
+// okxMaxCandles is the maximum "limit" accepted by /api/v5/market/candles
+const okxMaxCandles = 300
+
+// okxBars maps intervals to OKX bar codes (daily/weekly aligned to UTC like Binance)
+var okxBars = map[BinanceInterval]string{
+	Interval1m:  "1m",
+	Interval5m:  "5m",
+	Interval15m: "15m",
+	Interval30m: "30m",
+	Interval1h:  "1H",
+	Interval4h:  "4H",
+	Interval1d:  "1Dutc",
+	Interval1w:  "1Wutc",
+}
+
+// OKXProvider serves OKX spot candles
+type OKXProvider struct {
+	BaseURL string // overridable for recorded fixtures
+}
+
+// NewOKXProvider creates a provider for the public OKX API
+func NewOKXProvider() OKXProvider {
+	return OKXProvider{BaseURL: OKXBaseURL}
+}
+
+// Name returns the provider name
+func (OKXProvider) Name() string { return "OKX" }
+
+// Market returns MarketCrypto
+func (OKXProvider) Market() Market { return MarketCrypto }
+
+// NormalizeSymbol converts input to an OKX instrument ID (e.g., "btcusdt" -> "BTC-USDT")
+func (OKXProvider) NormalizeSymbol(input string) (string, string, error) {
+	pair, err := ParseCryptoPair(input)
+	if err != nil {
+		return "", "", err
+	}
+	return pair.Base + "-" + pair.Quote, pair.String(), nil
+}
+
+// get calls a v5 endpoint and returns the data payload
+func (p OKXProvider) get(ctx context.Context, path string, query url.Values) (json.RawMessage, error) {
+	body, _, err := DefaultHTTPClient.Get(ctx, fmt.Sprintf("%s%s?%s", p.BaseURL, path, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("OKX API error: %w", err)
+	}
+	return parseOKXResponse(body)
+}
+
+// parseOKXResponse unwraps the {"code","msg","data"} envelope
+// Code 51001 (instrument does not exist) is reported as errSymbolNotListed
+func parseOKXResponse(body []byte) (json.RawMessage, error) {
+	var resp struct {
+		Code string          `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse OKX JSON: %w", err)
+	}
+	switch resp.Code {
+	case "0":
+		return resp.Data, nil
+	case "51001":
+		return nil, fmt.Errorf("OKX: %s: %w", resp.Msg, errSymbolNotListed)
+	default:
+		return nil, fmt.Errorf("OKX error %s: %s", resp.Code, resp.Msg)
+	}
+}
+
+// FetchCandles fetches spot candles, paginating backwards with "after"
+func (p OKXProvider) FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	bar, ok := okxBars[interval]
+	if !ok {
+		return nil, fmt.Errorf("OKX does not support interval %s", interval)
+	}
+	if limit < 1 {
+		limit = 200
+	}
+
+	return fetchCandlePages(ctx, limit, okxMaxCandles, func(before time.Time, n int) ([]Candlestick, error) {
+		query := url.Values{}
+		query.Set("instId", symbol)
+		query.Set("bar", bar)
+		query.Set("limit", strconv.Itoa(n))
+		if !before.IsZero() {
+			query.Set("after", strconv.FormatInt(before.UnixMilli(), 10)) // exclusive
+		}
+		data, err := p.get(ctx, "/api/v5/market/candles", query)
+		if err != nil {
+			return nil, err
+		}
+		return parseOKXCandles(data, interval)
+	})
+}
+
+// parseOKXCandles parses data: [ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm], newest first
+func parseOKXCandles(data json.RawMessage, interval BinanceInterval) ([]Candlestick, error) {
+	var raw [][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OKX candles: %w", err)
+	}
+
+	candles := make([]Candlestick, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 6 {
+			continue
+		}
+		ts, _ := strconv.ParseInt(item[0], 10, 64)
+		open, _ := strconv.ParseFloat(item[1], 64)
+		high, _ := strconv.ParseFloat(item[2], 64)
+		low, _ := strconv.ParseFloat(item[3], 64)
+		close, _ := strconv.ParseFloat(item[4], 64)
+		volume, _ := strconv.ParseFloat(item[5], 64)
+
+		candle := exchangeCandle(time.UnixMilli(ts), interval, open, high, low, close, volume)
+		if len(item) >= 8 {
+			candle.QuoteVolume, _ = strconv.ParseFloat(item[7], 64)
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// GetCurrentPrice returns the last traded price from /api/v5/market/ticker
+func (p OKXProvider) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	query := url.Values{}
+	query.Set("instId", symbol)
+	data, err := p.get(ctx, "/api/v5/market/ticker", query)
+	if err != nil {
+		return 0, err
+	}
+	return parseOKXTicker(data, symbol)
+}
+
+// parseOKXTicker reads "last" from data[0]
+func parseOKXTicker(data json.RawMessage, symbol string) (float64, error) {
+	var raw []struct {
+		Last string `json:"last"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return 0, fmt.Errorf("failed to parse OKX ticker: %w", err)
+	}
+	if len(raw) == 0 {
+		return 0, fmt.Errorf("OKX: no ticker for %s: %w", symbol, errSymbolNotListed)
+	}
+	return strconv.ParseFloat(raw[0].Last, 64)
+}
+
+// ValidateSymbol checks the instrument through its ticker
+func (p OKXProvider) ValidateSymbol(ctx context.Context, symbol string) (bool, error) {
+	return validateByPrice(ctx, p, symbol)
+}
+
+// SupportedIntervals returns the intervals with a native OKX bar
+func (OKXProvider) SupportedIntervals() []BinanceInterval {
+	return []BinanceInterval{Interval1m, Interval5m, Interval15m, Interval30m, Interval1h, Interval4h, Interval1d, Interval1w}
+}
+
+// Precision borrows the Binance tick size of the same pair
+func (OKXProvider) Precision(symbol string) InstrumentPrecision {
+	pair, _ := ParseCryptoPair(symbol)
+	return cryptoPairPrecision(pair)
+}
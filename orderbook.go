@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Order book analysis settings
+const (
+	orderBookDepthLimit   = 5000 // max levels per side returned by /api/v3/depth (highest request weight)
+	orderBookDefaultLimit = 1000 // levels fetched first; usually enough to cover the wall range
+	orderBookMaxWalls     = 3    // walls reported per side
+	orderBookWallFactor   = 5.0  // wall = level notional >= factor x average level notional
+)
+
+// DefaultOrderBookWallRange is the percent from mid price searched for walls and imbalance
+const DefaultOrderBookWallRange = 2.0
+
+// orderBookBands are the cumulative depth distances (percent from mid price)
+var orderBookBands = []float64{0.1, 0.25, 0.5, 1.0, 2.0}
+
+// OrderBookLevel is one price level of the book
+type OrderBookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBook is a depth snapshot, bids sorted high to low and asks low to high
+type OrderBook struct {
+	Symbol       string
+	LastUpdateID int64
+	Bids         []OrderBookLevel
+	Asks         []OrderBookLevel
+}
+
+// DepthWall is an unusually large resting order
+type DepthWall struct {
+	Price    float64
+	Quantity float64
+	Notional float64
+	Distance float64 // percent from mid price
+	Strength float64 // notional relative to the average level
+}
+
+// DepthBand is the cumulative liquidity within Pct percent of mid price
+type DepthBand struct {
+	Pct         float64
+	BidNotional float64
+	AskNotional float64
+	Imbalance   float64 // -1 (all asks) .. +1 (all bids)
+	Complete    bool    // false when the snapshot does not reach this distance on both sides
+}
+
+// OrderBookSummary is the analyzed view of an order book snapshot
+type OrderBookSummary struct {
+	Symbol      string
+	MidPrice    float64
+	Spread      float64
+	SpreadPct   float64
+	BidCoverage float64 // percent below mid covered by the snapshot
+	AskCoverage float64 // percent above mid covered by the snapshot
+	WallRange   float64 // percent from mid price searched for walls and imbalance
+	Imbalance   float64 // within WallRange, -1 .. +1
+	BidWalls    []DepthWall
+	AskWalls    []DepthWall
+	Bands       []DepthBand
+}
+
+// FetchOrderBook fetches a depth snapshot from /api/v3/depth
+func FetchOrderBook(ctx context.Context, symbol string, limit int) (OrderBook, error) {
+	if limit < 1 || limit > orderBookDepthLimit {
+		limit = orderBookDepthLimit
+	}
+
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("limit", strconv.Itoa(limit))
+
+	body, err := fetchBinanceAPI(ctx, "/api/v3/depth", query)
+	if err != nil {
+		return OrderBook{}, err
+	}
+
+	var raw struct {
+		LastUpdateID int64       `json:"lastUpdateId"`
+		Bids         [][2]string `json:"bids"`
+		Asks         [][2]string `json:"asks"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return OrderBook{}, fmt.Errorf("failed to parse depth JSON: %w", err)
+	}
+
+	book := OrderBook{
+		Symbol:       symbol,
+		LastUpdateID: raw.LastUpdateID,
+		Bids:         parseDepthLevels(raw.Bids),
+		Asks:         parseDepthLevels(raw.Asks),
+	}
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return book, fmt.Errorf("empty order book for %s", symbol)
+	}
+	return book, nil
+}
+
+// FetchOrderBookRange fetches orderBookDefaultLimit levels and only requests the full
+// orderBookDepthLimit snapshot when that does not reach rangePct percent from mid on both sides
+func FetchOrderBookRange(ctx context.Context, symbol string, rangePct float64) (OrderBook, error) {
+	book, err := FetchOrderBook(ctx, symbol, orderBookDefaultLimit)
+	if err != nil {
+		return book, err
+	}
+	bidCoverage, askCoverage := bookCoverage(book)
+	truncated := len(book.Bids) >= orderBookDefaultLimit || len(book.Asks) >= orderBookDefaultLimit
+	if truncated && (bidCoverage < rangePct || askCoverage < rangePct) {
+		return FetchOrderBook(ctx, symbol, orderBookDepthLimit)
+	}
+	return book, nil
+}
+
+// bookCoverage returns how far (percent from mid) the snapshot reaches below and above mid
+func bookCoverage(book OrderBook) (bid, ask float64) {
+	mid := (book.Bids[0].Price + book.Asks[0].Price) / 2
+	return (mid - book.Bids[len(book.Bids)-1].Price) / mid * 100, (book.Asks[len(book.Asks)-1].Price - mid) / mid * 100
+}
+
+// parseDepthLevels converts [price, qty] string pairs
+func parseDepthLevels(raw [][2]string) []OrderBookLevel {
+	levels := make([]OrderBookLevel, 0, len(raw))
+	for _, r := range raw {
+		price, _ := strconv.ParseFloat(r[0], 64)
+		qty, _ := strconv.ParseFloat(r[1], 64)
+		if price > 0 && qty > 0 {
+			levels = append(levels, OrderBookLevel{Price: price, Quantity: qty})
+		}
+	}
+	return levels
+}
+
+// AnalyzeOrderBook computes imbalance and liquidity walls within wallRange percent of mid,
+// and the cumulative depth bands
+func AnalyzeOrderBook(book OrderBook, wallRange float64) OrderBookSummary {
+	summary := OrderBookSummary{Symbol: book.Symbol, WallRange: wallRange}
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return summary
+	}
+
+	bestBid, bestAsk := book.Bids[0].Price, book.Asks[0].Price
+	mid := (bestBid + bestAsk) / 2
+	summary.MidPrice = mid
+	summary.Spread = bestAsk - bestBid
+	summary.SpreadPct = summary.Spread / mid * 100
+	summary.BidCoverage, summary.AskCoverage = bookCoverage(book)
+
+	// Imbalance within the wall range
+	bidNotional := depthNotional(book.Bids, mid, wallRange)
+	askNotional := depthNotional(book.Asks, mid, wallRange)
+	summary.Imbalance = depthImbalance(bidNotional, askNotional)
+
+	summary.BidWalls = findDepthWalls(book.Bids, mid, wallRange)
+	summary.AskWalls = findDepthWalls(book.Asks, mid, wallRange)
+
+	for _, pct := range orderBookBands {
+		bid := depthNotional(book.Bids, mid, pct)
+		ask := depthNotional(book.Asks, mid, pct)
+		summary.Bands = append(summary.Bands, DepthBand{
+			Pct:         pct,
+			BidNotional: bid,
+			AskNotional: ask,
+			Imbalance:   depthImbalance(bid, ask),
+			Complete:    summary.BidCoverage >= pct && summary.AskCoverage >= pct,
+		})
+	}
+
+	return summary
+}
+
+// depthNotional sums price*qty of levels within pct percent of mid
+func depthNotional(levels []OrderBookLevel, mid, pct float64) float64 {
+	total := 0.0
+	for _, l := range levels {
+		if distancePct(l.Price, mid) > pct {
+			break // levels are sorted away from mid
+		}
+		total += l.Price * l.Quantity
+	}
+	return total
+}
+
+// depthImbalance returns (bid-ask)/(bid+ask)
+func depthImbalance(bid, ask float64) float64 {
+	if bid+ask == 0 {
+		return 0
+	}
+	return (bid - ask) / (bid + ask)
+}
+
+// distancePct returns the absolute distance of price from mid in percent
+func distancePct(price, mid float64) float64 {
+	d := (price - mid) / mid * 100
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// findDepthWalls returns the largest levels within wallRange percent of mid that stand out
+// from the average level size
+func findDepthWalls(levels []OrderBookLevel, mid, wallRange float64) []DepthWall {
+	var inRange []OrderBookLevel
+	total := 0.0
+	for _, l := range levels {
+		if distancePct(l.Price, mid) > wallRange {
+			break
+		}
+		inRange = append(inRange, l)
+		total += l.Price * l.Quantity
+	}
+	if len(inRange) == 0 {
+		return nil
+	}
+	avg := total / float64(len(inRange))
+
+	sort.Slice(inRange, func(i, j int) bool {
+		return inRange[i].Price*inRange[i].Quantity > inRange[j].Price*inRange[j].Quantity
+	})
+
+	var walls []DepthWall
+	for _, l := range inRange {
+		notional := l.Price * l.Quantity
+		if notional < avg*orderBookWallFactor || len(walls) >= orderBookMaxWalls {
+			break
+		}
+		walls = append(walls, DepthWall{
+			Price:    l.Price,
+			Quantity: l.Quantity,
+			Notional: notional,
+			Distance: (l.Price - mid) / mid * 100,
+			Strength: notional / avg,
+		})
+	}
+	return walls
+}
+
+// FormatOrderBookForAI formats the order book summary as a data context section
+func FormatOrderBookForAI(s OrderBookSummary, prec InstrumentPrecision) string {
+	var sb strings.Builder
+	p := prec.Format
+
+	sb.WriteString("--- ORDER BOOK (Binance Spot Depth) ---\n")
+	sb.WriteString(fmt.Sprintf("Mid Price: %s | Spread: %s (%.4f%%)\n", p(s.MidPrice), p(s.Spread), s.SpreadPct))
+	sb.WriteString(fmt.Sprintf("Snapshot Coverage: -%.2f%% / +%.2f%%\n", s.BidCoverage, s.AskCoverage))
+	sb.WriteString(fmt.Sprintf("Bid/Ask Imbalance (±%.1f%%): %+.2f (%s)\n", s.WallRange, s.Imbalance, describeImbalance(s.Imbalance)))
+
+	sb.WriteString("Cumulative Depth:\n")
+	for _, b := range s.Bands {
+		partial := ""
+		if !b.Complete {
+			partial = " (partial)"
+		}
+		sb.WriteString(fmt.Sprintf("  ±%.2f%%: Bids %.0f | Asks %.0f | Imbalance %+.2f%s\n", b.Pct, b.BidNotional, b.AskNotional, b.Imbalance, partial))
+	}
+
+	writeWalls := func(label string, walls []DepthWall) {
+		if len(walls) == 0 {
+			sb.WriteString(fmt.Sprintf("%s: none\n", label))
+			return
+		}
+		sb.WriteString(fmt.Sprintf("%s:\n", label))
+		for _, w := range walls {
+			sb.WriteString(fmt.Sprintf("  %s (%+.2f%%): %.4f qty, %.0f notional, %.1fx avg\n", p(w.Price), w.Distance, w.Quantity, w.Notional, w.Strength))
+		}
+	}
+	writeWalls("Bid Walls (support)", s.BidWalls)
+	writeWalls("Ask Walls (resistance)", s.AskWalls)
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// describeImbalance labels an imbalance value
+func describeImbalance(imbalance float64) string {
+	switch {
+	case imbalance >= 0.2:
+		return "BID HEAVY"
+	case imbalance <= -0.2:
+		return "ASK HEAVY"
+	default:
+		return "BALANCED"
+	}
+}
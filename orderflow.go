@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// orderFlowRecent is the number of recent candles compared against the full window
+const orderFlowRecent = 20
+
+// OrderFlowMetrics summarizes taker buy/sell activity derived from kline data
+type OrderFlowMetrics struct {
+	TakerBuyVolume   float64 // base volume bought by takers
+	TakerSellVolume  float64 // base volume sold by takers
+	BuyRatio         float64 // taker buy share of volume, percent
+	RecentBuyRatio   float64 // same over the last orderFlowRecent candles
+	Pressure         string  // BUYERS, SELLERS, NEUTRAL
+	CVD              float64 // cumulative volume delta over the window
+	RecentCVDChange  float64 // CVD change over the last orderFlowRecent candles
+	CVDDivergence    string  // NONE, BULLISH, BEARISH
+	TotalTrades      int64
+	AvgTrades        float64 // trades per candle
+	TradeIntensity   float64 // recent trades per candle / AvgTrades
+	AvgTradeSize     float64 // quote volume per trade
+	RecentTradeSize  float64 // quote volume per trade over the recent candles
+	TotalQuoteVolume float64
+}
+
+// AnalyzeOrderFlow derives buy/sell pressure, CVD and trade intensity from candles
+// Returns nil when the candles carry no taker data (non-Binance providers)
+func AnalyzeOrderFlow(candles []Candlestick) *OrderFlowMetrics {
+	hasFlow := false
+	for _, c := range candles {
+		if c.Trades > 0 {
+			hasFlow = true
+			break
+		}
+	}
+	if !hasFlow {
+		return nil
+	}
+
+	m := &OrderFlowMetrics{}
+	recentStart := len(candles) - orderFlowRecent
+	if recentStart < 0 {
+		recentStart = 0
+	}
+
+	var recentBuy, recentVolume, recentQuote float64
+	var recentTrades int64
+	cvdAtRecentStart := 0.0
+	for i, c := range candles {
+		if i == recentStart {
+			cvdAtRecentStart = m.CVD
+		}
+
+		sell := c.Volume - c.TakerBuyBase
+		m.TakerBuyVolume += c.TakerBuyBase
+		m.TakerSellVolume += sell
+		m.CVD += c.TakerBuyBase - sell
+		m.TotalTrades += c.Trades
+		m.TotalQuoteVolume += c.QuoteVolume
+
+		if i >= recentStart {
+			recentBuy += c.TakerBuyBase
+			recentVolume += c.Volume
+			recentQuote += c.QuoteVolume
+			recentTrades += c.Trades
+		}
+	}
+	m.RecentCVDChange = m.CVD - cvdAtRecentStart
+
+	if total := m.TakerBuyVolume + m.TakerSellVolume; total > 0 {
+		m.BuyRatio = m.TakerBuyVolume / total * 100
+	}
+	if recentVolume > 0 {
+		m.RecentBuyRatio = recentBuy / recentVolume * 100
+	}
+
+	switch {
+	case m.RecentBuyRatio >= 55:
+		m.Pressure = "BUYERS"
+	case m.RecentBuyRatio <= 45:
+		m.Pressure = "SELLERS"
+	default:
+		m.Pressure = "NEUTRAL"
+	}
+
+	// Price and CVD moving in opposite directions over the recent candles
+	m.CVDDivergence = "NONE"
+	priceChange := candles[len(candles)-1].Close - candles[recentStart].Open
+	if priceChange > 0 && m.RecentCVDChange < 0 {
+		m.CVDDivergence = "BEARISH"
+	} else if priceChange < 0 && m.RecentCVDChange > 0 {
+		m.CVDDivergence = "BULLISH"
+	}
+
+	m.AvgTrades = float64(m.TotalTrades) / float64(len(candles))
+	recentCount := len(candles) - recentStart
+	if m.AvgTrades > 0 {
+		m.TradeIntensity = float64(recentTrades) / float64(recentCount) / m.AvgTrades
+	}
+	if m.TotalTrades > 0 {
+		m.AvgTradeSize = m.TotalQuoteVolume / float64(m.TotalTrades)
+	}
+	if recentTrades > 0 {
+		m.RecentTradeSize = recentQuote / float64(recentTrades)
+	}
+
+	return m
+}
+
+// FormatOrderFlowForAI formats order flow metrics as lines of a timeframe section
+func FormatOrderFlowForAI(m *OrderFlowMetrics) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Taker Buy/Sell: %.2f / %.2f (Buy %.1f%%, last %d: %.1f%%) | Pressure: %s\n",
+		m.TakerBuyVolume, m.TakerSellVolume, m.BuyRatio, orderFlowRecent, m.RecentBuyRatio, m.Pressure))
+	sb.WriteString(fmt.Sprintf("CVD: %+.2f | Last %d Candles: %+.2f | Divergence: %s\n",
+		m.CVD, orderFlowRecent, m.RecentCVDChange, m.CVDDivergence))
+	sb.WriteString(fmt.Sprintf("Trades/Candle: %.0f | Intensity: %.2fx | Avg Trade Size: %.2f (recent %.2f) quote\n",
+		m.AvgTrades, m.TradeIntensity, m.AvgTradeSize, m.RecentTradeSize))
+
+	return sb.String()
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Candlestick pattern recognition with trend context and strength scores
+
+const (
+	patternLookback   = 14  // candles before the pattern used for average body/range/volume
+	patternTrendBars  = 10  // candles before the pattern used for the trend context
+	patternTrendRange = 1.5 // net move (in average ranges) that makes a trend
+	patternHighVolume = 1.5 // volume multiple of the average that adds strength
+)
+
+// Pattern trend contexts
+const (
+	TrendUp    = "UPTREND"
+	TrendDown  = "DOWNTREND"
+	TrendRange = "RANGE"
+)
+
+// CandlePattern is a pattern completed on a candle
+type CandlePattern struct {
+	Name      string // e.g., "Hammer", "Bearish Engulfing"
+	Direction string // BULLISH, BEARISH or NEUTRAL
+	Strength  int    // 0-100
+	Candles   int    // candles forming the pattern
+	Context   string // trend before the pattern: UPTREND, DOWNTREND, RANGE
+}
+
+// candleShape holds the measurements used by the pattern rules
+type candleShape struct {
+	body, rng, upper, lower float64
+	bull, bear              bool
+}
+
+// shapeOf measures a candle
+func shapeOf(c Candlestick) candleShape {
+	top, bottom := math.Max(c.Open, c.Close), math.Min(c.Open, c.Close)
+	return candleShape{
+		body:  top - bottom,
+		rng:   c.High - c.Low,
+		upper: c.High - top,
+		lower: bottom - c.Low,
+		bull:  c.Close > c.Open,
+		bear:  c.Close < c.Open,
+	}
+}
+
+// patternStats are averages of the candles before a pattern
+type patternStats struct {
+	body, rng, volume float64
+}
+
+// statsBefore averages body, range and volume over up to patternLookback candles before end
+func statsBefore(candles []Candlestick, end int) patternStats {
+	start := max(0, end-patternLookback)
+	var s patternStats
+	if end <= start {
+		return s
+	}
+	for _, c := range candles[start:end] {
+		sh := shapeOf(c)
+		s.body += sh.body
+		s.rng += sh.rng
+		s.volume += c.Volume
+	}
+	n := float64(end - start)
+	s.body /= n
+	s.rng /= n
+	s.volume /= n
+	return s
+}
+
+// trendBefore classifies the net move of the patternTrendBars candles before start
+func trendBefore(candles []Candlestick, start int, avgRange float64) string {
+	from := start - patternTrendBars
+	if from < 0 || avgRange <= 0 {
+		return TrendRange
+	}
+	move := candles[start-1].Close - candles[from].Close
+	switch {
+	case move > avgRange*patternTrendRange:
+		return TrendUp
+	case move < -avgRange*patternTrendRange:
+		return TrendDown
+	default:
+		return TrendRange
+	}
+}
+
+// DetectCandlePatterns returns the patterns completed on candle i, strongest first
+func DetectCandlePatterns(candles []Candlestick, i int) []CandlePattern {
+	if i < 0 || i >= len(candles) {
+		return nil
+	}
+	stats := statsBefore(candles, i)
+	var found []CandlePattern
+	add := func(name, direction string, base, size int) {
+		start := i - size + 1
+		context := trendBefore(candles, start, statsBefore(candles, start).rng)
+		found = append(found, CandlePattern{
+			Name:      name,
+			Direction: direction,
+			Strength:  patternStrength(base, direction, context, candles[i], stats),
+			Candles:   size,
+			Context:   context,
+		})
+	}
+
+	cur := shapeOf(candles[i])
+	c := candles[i]
+
+	// Single candle: pin bars, named by the trend they appear in
+	if cur.rng > 0 && cur.body <= cur.rng*0.35 {
+		context := trendBefore(candles, i, stats.rng)
+		switch {
+		case cur.lower >= cur.rng*0.6 && cur.upper <= cur.rng*0.15:
+			switch context {
+			case TrendDown:
+				add("Hammer", SMCBullish, 60, 1)
+			case TrendUp:
+				add("Hanging Man", SMCBearish, 45, 1)
+			default:
+				add("Bullish Pin Bar", SMCBullish, 50, 1)
+			}
+		case cur.upper >= cur.rng*0.6 && cur.lower <= cur.rng*0.15:
+			switch context {
+			case TrendUp:
+				add("Shooting Star", SMCBearish, 60, 1)
+			case TrendDown:
+				add("Inverted Hammer", SMCBullish, 45, 1)
+			default:
+				add("Bearish Pin Bar", SMCBearish, 50, 1)
+			}
+		}
+	}
+	if i < 1 {
+		return strongestFirst(found)
+	}
+
+	// Two candles
+	p := candles[i-1]
+	prev := shapeOf(p)
+	prevTop, prevBottom := math.Max(p.Open, p.Close), math.Min(p.Open, p.Close)
+	curTop, curBottom := math.Max(c.Open, c.Close), math.Min(c.Open, c.Close)
+
+	engulfing := false
+	switch {
+	case cur.bull && prev.bear && curTop >= prevTop && curBottom <= prevBottom && cur.body > prev.body:
+		add("Bullish Engulfing", SMCBullish, 65, 2)
+		engulfing = true
+	case cur.bear && prev.bull && curTop >= prevTop && curBottom <= prevBottom && cur.body > prev.body:
+		add("Bearish Engulfing", SMCBearish, 65, 2)
+		engulfing = true
+	}
+
+	if prev.body >= stats.body && cur.body <= prev.body*0.5 && curTop <= prevTop && curBottom >= prevBottom {
+		switch {
+		case prev.bear && !cur.bear:
+			add("Bullish Harami", SMCBullish, 45, 2)
+		case prev.bull && !cur.bull:
+			add("Bearish Harami", SMCBearish, 45, 2)
+		}
+	}
+
+	switch {
+	case c.High < p.High && c.Low > p.Low:
+		add("Inside Bar", "NEUTRAL", 30, 2)
+	case c.High > p.High && c.Low < p.Low && !engulfing:
+		direction := "NEUTRAL"
+		if cur.bull {
+			direction = SMCBullish
+		} else if cur.bear {
+			direction = SMCBearish
+		}
+		add("Outside Bar", direction, 40, 2)
+	}
+
+	tolerance := math.Max(stats.rng*0.05, c.Close*0.0002)
+	if prev.bear && cur.bull && math.Abs(c.Low-p.Low) <= tolerance {
+		add("Tweezer Bottom", SMCBullish, 50, 2)
+	}
+	if prev.bull && cur.bear && math.Abs(c.High-p.High) <= tolerance {
+		add("Tweezer Top", SMCBearish, 50, 2)
+	}
+	if i < 2 {
+		return strongestFirst(found)
+	}
+
+	// Three candles
+	f := candles[i-2]
+	first := shapeOf(f)
+	firstMid := (f.Open + f.Close) / 2
+	if first.body >= stats.body && prev.body <= first.body*0.3 {
+		switch {
+		case first.bear && cur.bull && c.Close > firstMid:
+			add("Morning Star", SMCBullish, 70, 3)
+		case first.bull && cur.bear && c.Close < firstMid:
+			add("Evening Star", SMCBearish, 70, 3)
+		}
+	}
+
+	soldiers := func(bull bool) bool {
+		for j := i - 2; j <= i; j++ {
+			s := shapeOf(candles[j])
+			if (bull && !s.bull) || (!bull && !s.bear) || s.body < stats.body*0.5 {
+				return false
+			}
+			// Closes near the extreme of the candle
+			if (bull && s.upper > s.body*0.5) || (!bull && s.lower > s.body*0.5) {
+				return false
+			}
+			if j > i-2 {
+				before := candles[j-1]
+				bTop, bBottom := math.Max(before.Open, before.Close), math.Min(before.Open, before.Close)
+				if candles[j].Open < bBottom || candles[j].Open > bTop {
+					return false
+				}
+				if (bull && candles[j].Close <= before.Close) || (!bull && candles[j].Close >= before.Close) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	switch {
+	case soldiers(true):
+		add("Three White Soldiers", SMCBullish, 70, 3)
+	case soldiers(false):
+		add("Three Black Crows", SMCBearish, 70, 3)
+	}
+
+	return strongestFirst(found)
+}
+
+// strongestFirst orders patterns by strength, keeping detection order on ties
+func strongestFirst(patterns []CandlePattern) []CandlePattern {
+	sort.SliceStable(patterns, func(i, j int) bool { return patterns[i].Strength > patterns[j].Strength })
+	return patterns
+}
+
+// patternStrength adjusts a pattern's base score for its trend context, volume and size
+// A reversal right after the move it reverses gains the most and a pattern with the trend a
+// little; high volume and a wide range add points, a narrow range takes them away
+func patternStrength(base int, direction, context string, c Candlestick, stats patternStats) int {
+	score := base
+	switch {
+	case direction == SMCBullish && context == TrendDown, direction == SMCBearish && context == TrendUp:
+		score += 15 // reversal at the end of a move
+	case direction == SMCBullish && context == TrendUp, direction == SMCBearish && context == TrendDown:
+		score += 5 // continuation
+	}
+	if stats.volume > 0 && c.Volume >= stats.volume*patternHighVolume {
+		score += 10
+	}
+	if stats.rng > 0 {
+		switch r := (c.High - c.Low) / stats.rng; {
+		case r >= 1.5:
+			score += 10
+		case r < 0.5:
+			score -= 10
+		}
+	}
+	return min(max(score, 0), 100)
+}
+
+// FormatCandlePatterns formats patterns for a candle line (empty when none)
+func FormatCandlePatterns(patterns []CandlePattern) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	parts := make([]string, len(patterns))
+	for i, p := range patterns {
+		parts[i] = fmt.Sprintf("%s %d (%s)", p.Name, p.Strength, p.Context)
+	}
+	return " | " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+)
+
+// Classic, Camarilla and Woodie pivot points from the prior daily and weekly candle
+
+// Pivot methods
+const (
+	PivotClassic   = "CLASSIC"
+	PivotCamarilla = "CAMARILLA"
+	PivotWoodie    = "WOODIE"
+)
+
+// Colors for pivot levels on charts
+var (
+	colorPivot           = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	colorPivotResistance = color.NRGBA{R: 239, G: 154, B: 154, A: 255}
+	colorPivotSupport    = color.NRGBA{R: 165, G: 214, B: 167, A: 255}
+)
+
+// PivotLevels are the pivot and its resistances/supports for one method
+type PivotLevels struct {
+	Method string
+	P      float64
+	R      []float64 // R1, R2, ...
+	S      []float64 // S1, S2, ...
+}
+
+// PivotPoints are the pivots for the period after a completed daily or weekly candle
+type PivotPoints struct {
+	Period  BinanceInterval // 1d or 1w
+	From    time.Time       // open time of the candle the pivots come from
+	High    float64
+	Low     float64
+	Close   float64
+	Methods []PivotLevels // classic, Camarilla, Woodie
+}
+
+// ComputePivotPoints computes the pivots of daily and weekly candles from the last completed
+// candle: the one before the live candle, or the last one once its period has closed (the
+// session close for session markets, see TradingCalendar.BarClose)
+// Returns nil for other intervals
+func ComputePivotPoints(candles []Candlestick, interval BinanceInterval, cal TradingCalendar, now time.Time) *PivotPoints {
+	if interval != Interval1d && interval != Interval1w {
+		return nil
+	}
+	i := len(candles) - 1
+	if i >= 0 && cal.BarClose(candles[i], interval).After(now) {
+		i--
+	}
+	if i < 0 {
+		return nil
+	}
+
+	c := candles[i]
+	h, l, cl := c.High, c.Low, c.Close
+	rng := h - l
+	if rng <= 0 {
+		return nil
+	}
+
+	p := (h + l + cl) / 3
+	classic := PivotLevels{Method: PivotClassic, P: p,
+		R: []float64{2*p - l, p + rng, h + 2*(p-l)},
+		S: []float64{2*p - h, p - rng, l - 2*(h-p)},
+	}
+
+	camarilla := PivotLevels{Method: PivotCamarilla, P: p}
+	for _, div := range []float64{12, 6, 4, 2} {
+		camarilla.R = append(camarilla.R, cl+rng*1.1/div)
+		camarilla.S = append(camarilla.S, cl-rng*1.1/div)
+	}
+
+	wp := (h + l + 2*cl) / 4
+	woodie := PivotLevels{Method: PivotWoodie, P: wp,
+		R: []float64{2*wp - l, wp + rng, h + 2*(wp-l)},
+		S: []float64{2*wp - h, wp - rng, l - 2*(h-wp)},
+	}
+
+	return &PivotPoints{Period: interval, From: c.OpenTime, High: h, Low: l, Close: cl,
+		Methods: []PivotLevels{classic, camarilla, woodie}}
+}
+
+// pivotPeriodName names the period the pivots apply to
+func pivotPeriodName(period BinanceInterval) string {
+	if period == Interval1w {
+		return "WEEKLY"
+	}
+	return "DAILY"
+}
+
+// FormatPivotPointsForAI formats the pivots as lines of a timeframe section
+func FormatPivotPointsForAI(pp *PivotPoints, prec InstrumentPrecision) string {
+	p := prec.Format
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s Pivot Points (from %s candle H %s | L %s | C %s):\n",
+		pivotPeriodName(pp.Period), pp.From.UTC().Format("2006-01-02"), p(pp.High), p(pp.Low), p(pp.Close)))
+	for _, m := range pp.Methods {
+		var parts []string
+		for i := len(m.R) - 1; i >= 0; i-- {
+			parts = append(parts, fmt.Sprintf("R%d %s", i+1, p(m.R[i])))
+		}
+		parts = append(parts, fmt.Sprintf("P %s", p(m.P)))
+		for i, s := range m.S {
+			parts = append(parts, fmt.Sprintf("S%d %s", i+1, p(s)))
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %s\n", m.Method, strings.Join(parts, " | ")))
+	}
+	return sb.String()
+}
+
+// PivotAnnotations draws the classic pivots of the daily and weekly summaries as levels from the
+// start of the period they apply to, labelled with the period ("D P", "D R1", "W S1", ...)
+func PivotAnnotations(summaries []CandleDataSummary) []ChartAnnotation {
+	var out []ChartAnnotation
+	for _, s := range summaries {
+		if s.Pivots == nil {
+			continue
+		}
+		prefix := pivotPeriodName(s.Pivots.Period)[:1]
+		from := s.Pivots.From.Add(IntervalDuration(s.Pivots.Period))
+		classic := s.Pivots.Methods[0]
+		add := func(label string, price float64, c color.NRGBA) {
+			out = append(out, ChartAnnotation{Kind: AnnotationLevel, Label: prefix + " " + label, Source: SourceAlgo,
+				From: from, Price: price, Color: c, Dashed: s.Pivots.Period == Interval1w})
+		}
+		add("P", classic.P, colorPivot)
+		for i := range classic.R {
+			add(fmt.Sprintf("R%d", i+1), classic.R[i], colorPivotResistance)
+			add(fmt.Sprintf("S%d", i+1), classic.S[i], colorPivotSupport)
+		}
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultCryptoDecimals is used until the symbol catalog knows the tick size
+const defaultCryptoDecimals = 8
+
+// forexPipOverrides maps a base or quote asset to its pip size (default 0.0001)
+// Base assets are checked first so XAU/USD uses the gold pip
+var forexPipOverrides = map[string]float64{
+	"XAU": 0.1,
+	"XAG": 0.01,
+	"JPY": 0.01,
+	"HUF": 0.01,
+	"IDR": 1,
+}
+
+// InstrumentPrecision describes how prices of an instrument are quoted
+type InstrumentPrecision struct {
+	TickSize float64 // minimum price increment (0 = unknown)
+	PipSize  float64 // forex pip size (0 for non-forex)
+	Decimals int     // decimals used when displaying prices
+}
+
+// Format formats a price with the instrument's display decimals
+func (p InstrumentPrecision) Format(price float64) string {
+	return strconv.FormatFloat(price, 'f', p.Decimals, 64)
+}
+
+// Verb returns the printf verb for prices (e.g., "%.2f")
+func (p InstrumentPrecision) Verb() string {
+	return fmt.Sprintf("%%.%df", p.Decimals)
+}
+
+// Round snaps a price to the tick size (or display decimals when the tick is unknown)
+func (p InstrumentPrecision) Round(price float64) float64 {
+	if p.TickSize > 0 {
+		price = math.Round(price/p.TickSize) * p.TickSize
+	}
+	scale := math.Pow(10, float64(p.Decimals))
+	return math.Round(price*scale) / scale
+}
+
+// Pips converts a price distance into pips (0 when the instrument has no pip)
+func (p InstrumentPrecision) Pips(distance float64) float64 {
+	if p.PipSize <= 0 {
+		return 0
+	}
+	return distance / p.PipSize
+}
+
+// Describe returns a short Indonesian description for prompts
+func (p InstrumentPrecision) Describe() string {
+	desc := fmt.Sprintf("%d desimal", p.Decimals)
+	if p.PipSize > 0 {
+		desc += fmt.Sprintf(", 1 pip = %s", strconv.FormatFloat(p.PipSize, 'f', -1, 64))
+	} else if p.TickSize > 0 {
+		desc += fmt.Sprintf(", tick size %s", strconv.FormatFloat(p.TickSize, 'f', -1, 64))
+	}
+	return desc
+}
+
+// decimalsForStep returns the number of decimals of a step such as 0.001 (-> 3)
+func decimalsForStep(step float64) int {
+	for d := 0; d <= 10; d++ {
+		scaled := step * math.Pow(10, float64(d))
+		if math.Abs(scaled-math.Round(scaled)) < 1e-9 {
+			return d
+		}
+	}
+	return 10
+}
+
+// BinancePrecision returns the precision of a Binance symbol from DefaultSymbolCatalog
+func BinancePrecision(symbol string) InstrumentPrecision {
+	info, ok := DefaultSymbolCatalog.Get(symbol)
+	if !ok || info.TickSize <= 0 {
+		return InstrumentPrecision{Decimals: defaultCryptoDecimals}
+	}
+	return InstrumentPrecision{
+		TickSize: info.TickSize,
+		Decimals: decimalsForStep(info.TickSize),
+	}
+}
+
+// ForexPrecision returns the pip-based precision of a Yahoo forex symbol
+// Prices are shown with one fractional pip (e.g., 5 decimals for EUR/USD, 3 for USD/JPY)
+func ForexPrecision(symbol string) InstrumentPrecision {
+	base, quote := forexAssets(symbol)
+
+	pip := 0.0001
+	if p, ok := forexPipOverrides[base]; ok {
+		pip = p
+	} else if p, ok := forexPipOverrides[quote]; ok {
+		pip = p
+	}
+
+	decimals := decimalsForStep(pip) + 1
+	return InstrumentPrecision{
+		TickSize: pip / 10,
+		PipSize:  pip,
+		Decimals: decimals,
+	}
+}
+
+// forexAssets returns the base and quote currency of a Yahoo forex symbol
+func forexAssets(symbol string) (string, string) {
+	for _, pair := range CommonForexPairs {
+		if pair.Symbol == symbol {
+			return pair.BaseCurr, pair.QuoteCurr
+		}
+	}
+
+	pair := strings.TrimSuffix(symbol, "=X")
+	if len(pair) == 6 {
+		return pair[:3], pair[3:]
+	}
+	return "", ""
+}
+
+// parsePriceNumber parses a price that may contain thousands separators ("98,500.50")
+// A lone comma followed by other than 3 digits is treated as a decimal comma ("1,0850")
+func parsePriceNumber(s string) (float64, error) {
+	if strings.Contains(s, ",") {
+		if !strings.Contains(s, ".") && strings.Count(s, ",") == 1 && len(s)-strings.Index(s, ",")-1 != 3 {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	}
+	return strconv.ParseFloat(s, 64)
+}
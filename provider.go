@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Market identifies the asset class served by a data provider
+type Market string
+
+const (
+	MarketCrypto Market = "crypto"
+	MarketForex  Market = "forex"
+	MarketStocks Market = "stocks" // Stocks, indices and futures
+	// MarketImported marks candles uploaded by the user; no provider is registered for it
+	MarketImported Market = "imported"
+)
+
+// MarketDataProvider is a source of OHLCV data for one market
+// Intervals are always expressed as BinanceInterval so every provider
+// feeds the same analysis pipeline (AnalyzeCandlestickData, charts, prompts)
+type MarketDataProvider interface {
+	// Name returns a human-readable provider name (e.g., "Binance")
+	Name() string
+	// Market returns the asset class this provider serves
+	Market() Market
+	// NormalizeSymbol converts user input to the provider symbol and a display name
+	NormalizeSymbol(input string) (symbol string, displayName string, err error)
+	// FetchCandles fetches the latest `limit` candles for symbol/interval
+	FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error)
+	// GetCurrentPrice fetches the latest traded price
+	GetCurrentPrice(ctx context.Context, symbol string) (float64, error)
+	// ValidateSymbol checks if the symbol exists on the provider
+	ValidateSymbol(ctx context.Context, symbol string) (bool, error)
+	// SupportedIntervals lists the intervals FetchCandles can serve
+	SupportedIntervals() []BinanceInterval
+	// Precision returns the tick/pip size and display decimals of a symbol
+	Precision(symbol string) InstrumentPrecision
+}
+
+// Instrument is a symbol resolved to a specific provider
+type Instrument struct {
+	Symbol      string // Provider symbol (e.g., "BTCUSDT", "EURUSD=X")
+	DisplayName string // Human readable name (e.g., "BTCUSDT", "EUR/USD")
+	Market      Market
+	Provider    MarketDataProvider
+	Precision   InstrumentPrecision
+}
+
+// ProviderRegistry routes symbols to the providers registered for each market
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[Market][]MarketDataProvider
+}
+
+// NewProviderRegistry creates an empty registry
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{
+		providers: make(map[Market][]MarketDataProvider),
+	}
+}
+
+// Register adds a provider for its market
+// Providers are tried in registration order when resolving a symbol
+func (r *ProviderRegistry) Register(p MarketDataProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Market()] = append(r.providers[p.Market()], p)
+}
+
+// Providers returns the providers registered for a market
+func (r *ProviderRegistry) Providers(market Market) []MarketDataProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]MarketDataProvider(nil), r.providers[market]...)
+}
+
+// Resolve normalizes user input and returns the first provider of the market that accepts it
+func (r *ProviderRegistry) Resolve(market Market, input string) (Instrument, error) {
+	providers := r.Providers(market)
+	if len(providers) == 0 {
+		return Instrument{}, fmt.Errorf("no data provider registered for market: %s", market)
+	}
+
+	var lastErr error
+	for _, p := range providers {
+		symbol, displayName, err := p.NormalizeSymbol(input)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return Instrument{
+			Symbol:      symbol,
+			DisplayName: displayName,
+			Market:      market,
+			Provider:    p,
+			Precision:   p.Precision(symbol),
+		}, nil
+	}
+
+	return Instrument{}, lastErr
+}
+
+// ResolveWithFallback resolves input like Resolve, but confirms the symbol is listed and
+// falls back to the next provider of the market when it is missing
+// preferred (a provider name, case-insensitive) is tried first when set
+// Markets with a single provider skip the listing check, like Resolve
+func (r *ProviderRegistry) ResolveWithFallback(ctx context.Context, market Market, input, preferred string) (Instrument, error) {
+	providers := r.Providers(market)
+	if len(providers) == 0 {
+		return Instrument{}, fmt.Errorf("no data provider registered for market: %s", market)
+	}
+
+	if preferred != "" {
+		index := -1
+		var names []string
+		for i, p := range providers {
+			names = append(names, strings.ToLower(p.Name()))
+			if strings.EqualFold(p.Name(), preferred) {
+				index = i
+			}
+		}
+		if index < 0 {
+			return Instrument{}, fmt.Errorf("unknown exchange %s (available: %s)", preferred, strings.Join(names, ", "))
+		}
+		preferredProvider := providers[index]
+		providers = append([]MarketDataProvider{preferredProvider}, append(providers[:index:index], providers[index+1:]...)...)
+	}
+
+	var notFound *SymbolNotFoundError
+	var missing []string
+	for _, p := range providers {
+		symbol, displayName, err := p.NormalizeSymbol(input)
+		if err != nil {
+			if notFound == nil {
+				errors.As(err, &notFound)
+			}
+			missing = append(missing, p.Name())
+			continue
+		}
+
+		if len(providers) > 1 {
+			listed, err := p.ValidateSymbol(ctx, symbol)
+			if ctx.Err() != nil {
+				return Instrument{}, ctx.Err()
+			}
+			if !listed {
+				if err != nil {
+					log.Printf("⚠️ [PROVIDER] %s check failed for %s: %v", p.Name(), symbol, err)
+				}
+				missing = append(missing, p.Name())
+				continue
+			}
+		}
+		if len(missing) > 0 {
+			log.Printf("🔀 [PROVIDER] %s not available on %s, using %s", input, strings.Join(missing, ", "), p.Name())
+		}
+
+		return Instrument{
+			Symbol:      symbol,
+			DisplayName: displayName,
+			Market:      market,
+			Provider:    p,
+			Precision:   p.Precision(symbol),
+		}, nil
+	}
+
+	if notFound != nil {
+		return Instrument{}, notFound
+	}
+	return Instrument{}, fmt.Errorf("symbol %s not found on %s", input, strings.Join(missing, ", "))
+}
+
+// DefaultProviders is the registry used by the bot commands
+var DefaultProviders = newDefaultProviderRegistry()
+
+func newDefaultProviderRegistry() *ProviderRegistry {
+	r := NewProviderRegistry()
+	// Crypto exchanges in fallback order
+	r.Register(NewCachedProvider(BinanceProvider{}, DefaultCandleCache))
+	r.Register(NewCachedProvider(NewBybitProvider(), DefaultCandleCache))
+	r.Register(NewCachedProvider(NewOKXProvider(), DefaultCandleCache))
+	r.Register(NewCachedProvider(NewCoinbaseProvider(), DefaultCandleCache))
+	r.Register(NewCachedProvider(NewKrakenProvider(), DefaultCandleCache))
+	r.Register(NewCachedProvider(YahooProvider{}, DefaultCandleCache))
+	r.Register(NewCachedProvider(StockProvider{}, DefaultCandleCache))
+	return r
+}
+
+// GetTimeframesForMarket returns the timeframes to analyze for a market and trading mode
+func GetTimeframesForMarket(market Market, mode TradingMode) []BinanceInterval {
+	switch market {
+	case MarketForex:
+		yahooTimeframes := GetForexTimeframesForMode(mode)
+		timeframes := make([]BinanceInterval, 0, len(yahooTimeframes))
+		for _, tf := range yahooTimeframes {
+			timeframes = append(timeframes, ConvertYahooToBinanceInterval(tf))
+		}
+		return timeframes
+	case MarketStocks:
+		return GetStockTimeframesForMode(ExchangeUS, mode)
+	default:
+		return GetTimeframesForMode(mode)
+	}
+}
+
+// GetTimeframesForInstrument returns the timeframes to analyze for a resolved instrument
+// Stocks depend on the exchange session, other markets only on the market
+func GetTimeframesForInstrument(inst Instrument, mode TradingMode) []BinanceInterval {
+	if inst.Market == MarketStocks {
+		return GetStockTimeframesForMode(StockExchangeFor(inst.Symbol), mode)
+	}
+	return GetTimeframesForMarket(inst.Market, mode)
+}
+
+// GetChartIntervalForMarket returns the timeframe used for the entry chart
+// Crypto: 1H (4H for swing), Forex/Stocks: 1H (1D for swing)
+func GetChartIntervalForMarket(market Market, mode TradingMode) BinanceInterval {
+	if mode != TradingModeSwing {
+		return Interval1h
+	}
+	if market == MarketForex || market == MarketStocks {
+		return Interval1d
+	}
+	return Interval4h
+}
+
+// multiTimeframeWorkers bounds concurrent timeframe requests per analysis
+const multiTimeframeWorkers = 3
+
+// TimeframeError records a timeframe that could not be fetched
+type TimeframeError struct {
+	Interval BinanceInterval
+	Err      error
+}
+
+// MultiTimeframeResult holds the summaries that were fetched and the timeframes that failed
+type MultiTimeframeResult struct {
+	Summaries []CandleDataSummary // Successful timeframes, in requested order
+	Failed    []TimeframeError    // Timeframes that failed, in requested order
+	OrderBook *OrderBookSummary   // Optional depth snapshot (crypto only)
+}
+
+// FetchProviderMultiTimeframeData fetches and summarizes data for each timeframe from a provider
+// Timeframes are fetched concurrently by a bounded worker pool; a failing timeframe is
+// reported in Failed instead of aborting the analysis. An error is returned only when
+// every timeframe failed.
+// Timeframes the provider does not serve natively are resampled from a smaller interval
+func FetchProviderMultiTimeframeData(ctx context.Context, provider MarketDataProvider, symbol string, timeframes []BinanceInterval, candleLimit int) (MultiTimeframeResult, error) {
+	type job struct {
+		index    int
+		interval BinanceInterval
+	}
+
+	summaries := make([]CandleDataSummary, len(timeframes))
+	errs := make([]error, len(timeframes))
+	calendar := CalendarForMarket(provider.Market(), symbol)
+
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	workers := multiTimeframeWorkers
+	if len(timeframes) < workers {
+		workers = len(timeframes)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				candles, err := FetchCandlesWithResample(ctx, provider, symbol, j.interval, candleLimit, 0)
+				if err == nil && len(candles) == 0 {
+					err = fmt.Errorf("no candles returned")
+				}
+				if err != nil {
+					errs[j.index] = err
+					continue
+				}
+				summary := AnalyzeCandlestickData(candles, j.interval)
+				summary.Pivots = ComputePivotPoints(candles, j.interval, calendar, time.Now())
+				summary.Quality = AssessDataQuality(candles, j.interval, calendar, time.Now())
+				if !summary.Quality.Clean() {
+					log.Printf("⚠️ [QUALITY] %s %s: %s", symbol, j.interval, summary.Quality.Summary())
+				}
+				summaries[j.index] = summary
+			}
+		}()
+	}
+	for i, tf := range timeframes {
+		jobs <- job{index: i, interval: tf}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var result MultiTimeframeResult
+	for i, tf := range timeframes {
+		if errs[i] != nil {
+			result.Failed = append(result.Failed, TimeframeError{Interval: tf, Err: errs[i]})
+			continue
+		}
+		result.Summaries = append(result.Summaries, summaries[i])
+	}
+
+	if len(result.Summaries) == 0 && len(result.Failed) > 0 {
+		return result, fmt.Errorf("failed to fetch %s: %w", result.Failed[0].Interval, result.Failed[0].Err)
+	}
+	return result, nil
+}
+
+// FormatMissingTimeframes tells the AI which timeframes are unavailable
+func FormatMissingTimeframes(failed []TimeframeError) string {
+	if len(failed) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- MISSING TIMEFRAMES ---\n")
+	sb.WriteString("Data untuk timeframe berikut GAGAL diambil. Jangan mengarang data untuk timeframe ini; analisa dengan timeframe yang tersedia dan sebutkan keterbatasannya.\n")
+	for _, f := range failed {
+		sb.WriteString(fmt.Sprintf("  %s: unavailable (%v)\n", GetTimeframeName(f.Interval), f.Err))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// FormatMarketDataForAI formats multi-timeframe data using the market-specific formatter
+// Missing timeframes are listed after the data so the AI knows what it is not seeing
+func FormatMarketDataForAI(inst Instrument, data MultiTimeframeResult, mode TradingMode) string {
+	var formatted string
+	switch inst.Market {
+	case MarketForex:
+		formatted = FormatForexDataForAI(inst.Symbol, inst.DisplayName, inst.Precision, data.Summaries, mode)
+	case MarketStocks:
+		formatted = FormatStockDataForAI(inst.Symbol, inst.DisplayName, inst.Precision, data.Summaries, mode)
+	default:
+		formatted = FormatDataForAI(inst.Symbol, inst.Precision, data.Summaries, mode, data.OrderBook)
+	}
+	return formatted + FormatDataQualityForAI(data.Summaries) + FormatMissingTimeframes(data.Failed)
+}
+
+// GenerateMarketAnalysisPrompt creates the market-specific analysis prompt
+func GenerateMarketAnalysisPrompt(inst Instrument, mode TradingMode, dataContext string) string {
+	switch inst.Market {
+	case MarketForex:
+		return GenerateForexAnalysisPrompt(mode, inst.Symbol, inst.DisplayName, inst.Precision, dataContext)
+	case MarketStocks:
+		return GenerateStockAnalysisPrompt(mode, inst.Symbol, inst.DisplayName, inst.Precision, dataContext)
+	default:
+		return GenerateDataAnalysisPrompt(mode, inst.Symbol, inst.Precision, dataContext)
+	}
+}
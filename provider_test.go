@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// stubProvider is a crypto provider listing a fixed set of symbols
+type stubProvider struct {
+	name   string
+	listed map[string]bool
+	err    error // returned by ValidateSymbol for unlisted symbols
+	checks *[]string
+}
+
+func (p stubProvider) Name() string   { return p.name }
+func (p stubProvider) Market() Market { return MarketCrypto }
+func (p stubProvider) NormalizeSymbol(input string) (string, string, error) {
+	symbol := strings.ToUpper(input)
+	return p.name + ":" + symbol, symbol, nil
+}
+func (p stubProvider) FetchCandles(context.Context, string, BinanceInterval, int) ([]Candlestick, error) {
+	return nil, nil
+}
+func (p stubProvider) GetCurrentPrice(context.Context, string) (float64, error) { return 0, nil }
+func (p stubProvider) ValidateSymbol(_ context.Context, symbol string) (bool, error) {
+	*p.checks = append(*p.checks, symbol)
+	if p.listed[symbol] {
+		return true, nil
+	}
+	return false, p.err
+}
+func (p stubProvider) SupportedIntervals() []BinanceInterval { return nil }
+func (p stubProvider) Precision(string) InstrumentPrecision  { return InstrumentPrecision{} }
+
+func TestResolveWithFallback(t *testing.T) {
+	var checks []string
+	r := NewProviderRegistry()
+	r.Register(stubProvider{name: "Binance", listed: map[string]bool{"Binance:BTC": true}, checks: &checks})
+	r.Register(stubProvider{name: "Bybit", listed: map[string]bool{"Bybit:BTC": true, "Bybit:PEPE": true}, checks: &checks})
+	r.Register(stubProvider{name: "Kraken", err: errors.New("timeout"), checks: &checks})
+
+	tests := []struct {
+		input, preferred string
+		want             string // resolved symbol, empty for an error
+		checked          []string
+	}{
+		{"btc", "", "Binance:BTC", []string{"Binance:BTC"}},
+		{"pepe", "", "Bybit:PEPE", []string{"Binance:PEPE", "Bybit:PEPE"}},
+		{"btc", "bybit", "Bybit:BTC", []string{"Bybit:BTC"}},
+		{"btc", "KRAKEN", "Binance:BTC", []string{"Kraken:BTC", "Binance:BTC"}},
+		{"nope", "", "", []string{"Binance:NOPE", "Bybit:NOPE", "Kraken:NOPE"}},
+		{"btc", "ftx", "", nil},
+	}
+	for _, tt := range tests {
+		checks = nil
+		inst, err := r.ResolveWithFallback(context.Background(), MarketCrypto, tt.input, tt.preferred)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s@%s resolved to %s, want an error", tt.input, tt.preferred, inst.Symbol)
+			}
+		} else if err != nil || inst.Symbol != tt.want || inst.Provider.Name()+":"+inst.DisplayName != tt.want {
+			t.Errorf("%s@%s = %s, %v; want %s", tt.input, tt.preferred, inst.Symbol, err, tt.want)
+		}
+		if strings.Join(checks, ",") != strings.Join(tt.checked, ",") {
+			t.Errorf("%s@%s checked %v, want %v", tt.input, tt.preferred, checks, tt.checked)
+		}
+	}
+}
+
+func TestResolveWithFallbackSingleProvider(t *testing.T) {
+	var checks []string
+	r := NewProviderRegistry()
+	r.Register(stubProvider{name: "Binance", checks: &checks})
+
+	inst, err := r.ResolveWithFallback(context.Background(), MarketCrypto, "eth", "")
+	if err != nil || inst.Symbol != "Binance:ETH" {
+		t.Errorf("ResolveWithFallback = %s, %v; want Binance:ETH", inst.Symbol, err)
+	}
+	if len(checks) != 0 {
+		t.Errorf("a single provider was checked for %v, want no listing check", checks)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Data quality settings
+const (
+	gapTolerance      = 1.5 // spacing above this many intervals counts as a gap
+	staleFactor       = 3   // last bar is stale after this many intervals of trading time
+	maxReportedGaps   = 3   // gaps listed per timeframe in the AI context
+	maxReportedFills  = 5   // synthetic bar times listed per timeframe
+	forexShortBreak   = time.Hour
+	sessionShortBreak = 2 * time.Hour // lunch breaks (Jakarta, Tokyo, Hong Kong)
+)
+
+// forexWeekZone aligns the forex week (Sunday 22:00 to Friday 22:00 UTC) with calendar days
+var forexWeekZone = time.FixedZone("FX", 2*60*60)
+
+// TradingCalendar describes when a market trades, used to tell real gaps from closures
+type TradingCalendar struct {
+	Continuous bool           // trades 24/7 (crypto), every gap is real
+	Location   *time.Location // timezone of the trading day
+	Sessions   bool           // closes overnight (cash equities)
+	Open       time.Duration  // session open since local midnight (Sessions only)
+	Close      time.Duration  // session close since local midnight (Sessions only)
+	ShortBreak time.Duration  // intraday pauses up to this long are not gaps
+}
+
+// CalendarForMarket returns the trading calendar of a symbol
+func CalendarForMarket(market Market, symbol string) TradingCalendar {
+	switch market {
+	case MarketForex:
+		return TradingCalendar{Location: forexWeekZone, ShortBreak: forexShortBreak}
+	case MarketStocks:
+		exch := StockExchangeFor(symbol)
+		if exch.SessionLength() >= 24*time.Hour {
+			return TradingCalendar{Location: exch.location(), ShortBreak: forexShortBreak}
+		}
+		return TradingCalendar{
+			Location:   exch.location(),
+			Sessions:   true,
+			Open:       exch.Open,
+			Close:      exch.Close,
+			ShortBreak: sessionShortBreak,
+		}
+	default:
+		return TradingCalendar{Continuous: true, Location: time.UTC}
+	}
+}
+
+// DataGap is a run of bars missing between two consecutive candles
+type DataGap struct {
+	From    time.Time // open time of the last bar before the gap
+	To      time.Time // open time of the first bar after the gap
+	Missing int       // bars missing in between
+}
+
+// DataQualityReport describes how trustworthy a fetched candle series is
+type DataQualityReport struct {
+	Interval       BinanceInterval
+	Bars           int
+	MissingBars    int       // bars missing in unexpected gaps
+	Gaps           []DataGap // unexpected gaps, oldest first
+	SyntheticFills int       // bars whose OHLC was partly filled because the provider returned nulls
+	SyntheticTimes []time.Time
+	LastBarTime    time.Time     // open time of the last bar
+	LastBarAge     time.Duration // time since the last bar closed (0 while it is still forming)
+	Stale          bool          // last bar is older than staleFactor intervals of trading time
+}
+
+// Clean reports whether the series has no gaps, no synthetic bars and a fresh last bar
+func (r DataQualityReport) Clean() bool {
+	return r.MissingBars == 0 && r.SyntheticFills == 0 && !r.Stale
+}
+
+// AssessDataQuality checks candles for unexpected gaps, synthetic fills and a stale last bar
+func AssessDataQuality(candles []Candlestick, interval BinanceInterval, cal TradingCalendar, now time.Time) DataQualityReport {
+	report := DataQualityReport{Interval: interval, Bars: len(candles)}
+	if len(candles) == 0 {
+		return report
+	}
+
+	step := IntervalDuration(interval)
+	barTime := cal.tradingStep(step)
+	for i, c := range candles {
+		if c.Synthetic {
+			report.SyntheticFills++
+			report.SyntheticTimes = append(report.SyntheticTimes, c.OpenTime)
+		}
+		if i == 0 {
+			continue
+		}
+
+		prev := candles[i-1]
+		if float64(c.OpenTime.Sub(prev.OpenTime)) <= float64(step)*gapTolerance {
+			continue
+		}
+		// Only time the market was actually trading counts as missing
+		missingTime := cal.tradingDuration(prev.OpenTime.Add(step), c.OpenTime)
+		if missingTime <= cal.ShortBreak {
+			continue
+		}
+		missing := int((missingTime + barTime/2) / barTime)
+		if missing < 1 {
+			missing = 1
+		}
+		report.MissingBars += missing
+		report.Gaps = append(report.Gaps, DataGap{From: prev.OpenTime, To: c.OpenTime, Missing: missing})
+	}
+
+	last := candles[len(candles)-1]
+	report.LastBarTime = last.OpenTime
+	if age := now.Sub(last.CloseTime); age > 0 {
+		report.LastBarAge = age
+		report.Stale = cal.tradingDuration(last.CloseTime, now) > staleFactor*barTime
+	}
+
+	return report
+}
+
+// tradingStep returns the trading time covered by one bar
+// Daily and weekly bars of session markets only span the session hours
+func (cal TradingCalendar) tradingStep(step time.Duration) time.Duration {
+	if !cal.Sessions || step < 24*time.Hour {
+		return step
+	}
+	return step / (24 * time.Hour) * (cal.Close - cal.Open)
+}
+
+// tradingDuration returns how long the market was open during [from, to)
+func (cal TradingCalendar) tradingDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	return to.Sub(from) - cal.closedDuration(from, to)
+}
+
+// closedDuration returns how much of [from, to) the market was closed
+func (cal TradingCalendar) closedDuration(from, to time.Time) time.Duration {
+	if cal.Continuous || !to.After(from) {
+		return 0
+	}
+
+	from, to = from.In(cal.loc()), to.In(cal.loc())
+	closed := time.Duration(0)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for day.Before(to) {
+		next := day.AddDate(0, 0, 1)
+		if isWeekend(day) {
+			closed += overlap(from, to, day, next)
+		} else if cal.Sessions {
+			closed += overlap(from, to, day, day.Add(cal.Open))
+			closed += overlap(from, to, day.Add(cal.Close), next)
+		}
+		day = next
+	}
+	return closed
+}
+
+// BarClose returns when a bar is final: for daily and weekly bars of session markets the session
+// close of its day (of the Friday for weekly bars), since Yahoo stamps them with a 24h close time;
+// otherwise the bar's own close time
+func (cal TradingCalendar) BarClose(c Candlestick, interval BinanceInterval) time.Time {
+	if !cal.Sessions || (interval != Interval1d && interval != Interval1w) {
+		return c.CloseTime
+	}
+	open := c.OpenTime.In(cal.loc())
+	day := time.Date(open.Year(), open.Month(), open.Day(), 0, 0, 0, 0, open.Location())
+	if interval == Interval1w {
+		day = day.AddDate(0, 0, (int(time.Friday)-int(day.Weekday())+7)%7)
+	}
+	return day.Add(cal.Close)
+}
+
+// loc returns the calendar timezone (UTC when unset)
+func (cal TradingCalendar) loc() *time.Location {
+	if cal.Location == nil {
+		return time.UTC
+	}
+	return cal.Location
+}
+
+// isWeekend reports whether t falls on Saturday or Sunday
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// overlap returns the length of the intersection of [aStart, aEnd) and [bStart, bEnd)
+func overlap(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	start, end := aStart, aEnd
+	if bStart.After(start) {
+		start = bStart
+	}
+	if bEnd.Before(end) {
+		end = bEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// Summary returns a one-line description of the report for logs and the AI context
+func (r DataQualityReport) Summary() string {
+	if r.Clean() {
+		return "OK"
+	}
+
+	var parts []string
+	if r.MissingBars > 0 {
+		largest := r.Gaps[0]
+		for _, g := range r.Gaps {
+			if g.Missing > largest.Missing {
+				largest = g
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%d missing bars in %d gaps (largest %s -> %s, %d bars)",
+			r.MissingBars, len(r.Gaps), largest.From.Format("2006-01-02 15:04"), largest.To.Format("2006-01-02 15:04"), largest.Missing))
+	}
+	if r.SyntheticFills > 0 {
+		parts = append(parts, fmt.Sprintf("%d synthetic bars", r.SyntheticFills))
+	}
+	if r.Stale {
+		parts = append(parts, fmt.Sprintf("STALE: last bar %s closed %s ago", r.LastBarTime.Format("2006-01-02 15:04"), formatAge(r.LastBarAge)))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// formatAge formats a duration as hours or days
+func formatAge(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	}
+	if d >= time.Hour {
+		return fmt.Sprintf("%.1fh", d.Hours())
+	}
+	return fmt.Sprintf("%.0fm", d.Minutes())
+}
+
+// FormatDataQualityForAI lists data problems per timeframe so the AI does not analyze invented prices
+// Returns a single OK line when every timeframe is clean
+func FormatDataQualityForAI(summaries []CandleDataSummary) string {
+	clean := true
+	for _, s := range summaries {
+		if !s.Quality.Clean() {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return "Data Quality: OK (tidak ada gap, tidak ada bar sintetis, data terkini)\n\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- DATA QUALITY ---\n")
+	sb.WriteString("Data berikut TIDAK lengkap. Jangan menganggap harga di dalam gap atau bar sintetis sebagai harga nyata, dan jangan menaruh level entry/SL/TP berdasarkan bar tersebut. Jika data STALE, sebutkan bahwa harga terakhir mungkin bukan harga pasar saat ini.\n")
+	for _, s := range summaries {
+		q := s.Quality
+		sb.WriteString(fmt.Sprintf("  %s: %s\n", GetTimeframeName(s.Interval), q.Summary()))
+
+		for i, g := range q.Gaps {
+			if i >= maxReportedGaps {
+				sb.WriteString(fmt.Sprintf("    ... %d more gaps\n", len(q.Gaps)-maxReportedGaps))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("    Gap: %s -> %s (%d bars missing)\n", g.From.Format("2006-01-02 15:04"), g.To.Format("2006-01-02 15:04"), g.Missing))
+		}
+		if len(q.SyntheticTimes) > 0 {
+			var times []string
+			for i, t := range q.SyntheticTimes {
+				if i >= maxReportedFills {
+					times = append(times, fmt.Sprintf("+%d", len(q.SyntheticTimes)-maxReportedFills))
+					break
+				}
+				times = append(times, t.Format("01-02 15:04"))
+			}
+			sb.WriteString(fmt.Sprintf("    Synthetic: %s\n", strings.Join(times, ", ")))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
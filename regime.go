@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Market regime classifier: trend direction and strength from ADX and EMA slope, ranging/trending/
+// breakout state from ADX and Bollinger bandwidth, volatility as a percentile of the instrument's
+// own ATR history so that the same thresholds work for crypto, forex and stocks
+
+const (
+	regimeEMAPeriod    = 20   // EMA whose slope measures the trend
+	regimeSlopeBars    = 10   // bars over which the slope is measured
+	regimeADXTrending  = 25   // ADX at or above this is a trend
+	regimeMinStrength  = 40   // trend strength needed for TRENDING
+	regimeSqueezePct   = 20   // bandwidth percentile at or below this is a squeeze
+	regimeSqueezeBars  = 10   // a breakout must leave a squeeze within this many bars
+	regimeFullSlope    = 0.3  // slope (ATR per bar) that scores full marks
+	regimeLowVolPct    = 30   // ATR percentile below this is LOW volatility
+	regimeHighVolPct   = 70   // ATR percentile above this is HIGH volatility
+	regimeADXWeight    = 0.6  // share of ADX in the trend strength (the rest is slope)
+	regimeADXFloor     = 10.0 // ADX scoring 0
+	regimeADXFullMarks = 40.0 // ADX scoring full marks
+)
+
+// Regime states
+const (
+	RegimeTrending = "TRENDING"
+	RegimeRanging  = "RANGING"
+	RegimeBreakout = "BREAKOUT"
+)
+
+// MarketRegime classifies the market of one timeframe
+type MarketRegime struct {
+	State         string  // TRENDING, RANGING or BREAKOUT
+	Direction     string  // BULLISH, BEARISH or SIDEWAYS
+	Strength      int     // trend strength 0-100
+	ADX           float64 // ADX(14)
+	Slope         float64 // EMA(20) change per bar in ATRs
+	BandwidthPct  float64 // Bollinger bandwidth percentile over the history (0-100)
+	Volatility    string  // LOW, MEDIUM, HIGH
+	VolatilityPct float64 // ATR% percentile over the history (0-100)
+}
+
+// ClassifyRegime classifies the last candle against the history of candles
+// Returns nil while ADX or the slope is still warming up
+func ClassifyRegime(candles []Candlestick) *MarketRegime {
+	n := len(candles)
+	closes := Closes(candles)
+	dmi := DMI(candles, dmiPeriod)
+	atr := ATR(candles, atrPeriod)
+	ema := EMA(closes, regimeEMAPeriod)
+	if n <= regimeSlopeBars || math.IsNaN(Last(dmi.ADX)) || math.IsNaN(ema[n-1-regimeSlopeBars]) || Last(atr) <= 0 {
+		return nil
+	}
+
+	r := &MarketRegime{ADX: Last(dmi.ADX)}
+	r.Slope = (ema[n-1] - ema[n-1-regimeSlopeBars]) / regimeSlopeBars / Last(atr)
+
+	adxScore := clamp01((r.ADX - regimeADXFloor) / (regimeADXFullMarks - regimeADXFloor))
+	slopeScore := clamp01(math.Abs(r.Slope) / regimeFullSlope)
+	r.Strength = int(math.Round(100 * (regimeADXWeight*adxScore + (1-regimeADXWeight)*slopeScore)))
+
+	// Direction needs the DI lines and the slope to agree
+	plus, minus := Last(dmi.PlusDI), Last(dmi.MinusDI)
+	switch {
+	case r.Slope > 0 && plus > minus:
+		r.Direction = SMCBullish
+	case r.Slope < 0 && minus > plus:
+		r.Direction = SMCBearish
+	default:
+		r.Direction = "SIDEWAYS"
+	}
+
+	// Volatility relative to the instrument's own history
+	r.Volatility, r.VolatilityPct = ClassifyVolatility(candles, atr)
+
+	// Bandwidth squeeze followed by a close outside the bands is a breakout
+	bb := BollingerBands(closes, bollingerPeriod, bollingerStdDev)
+	bandwidth := make([]float64, n)
+	for i := range bandwidth {
+		bandwidth[i] = (bb.Upper[i] - bb.Lower[i]) / bb.Middle[i]
+	}
+	r.BandwidthPct = percentileRank(bandwidth)
+	squeeze := false
+	for i := max(0, n-regimeSqueezeBars); i < n; i++ {
+		if percentileRank(bandwidth[:i+1]) <= regimeSqueezePct {
+			squeeze = true
+			break
+		}
+	}
+	last := closes[n-1]
+	above, below := last > Last(bb.Upper), last < Last(bb.Lower)
+
+	switch {
+	case squeeze && (above || below):
+		r.State = RegimeBreakout
+		r.Direction = SMCBullish
+		if below {
+			r.Direction = SMCBearish
+		}
+	case r.ADX >= regimeADXTrending && r.Strength >= regimeMinStrength && r.Direction != "SIDEWAYS":
+		r.State = RegimeTrending
+	default:
+		r.State = RegimeRanging
+	}
+	return r
+}
+
+// ClassifyVolatility classifies the last candle's ATR% as LOW, MEDIUM or HIGH by its percentile
+// over the history; it only needs ATR, so short histories get it before the regime
+// Returns "" and NaN while ATR is still warming up
+func ClassifyVolatility(candles []Candlestick, atr []float64) (string, float64) {
+	atrPct := make([]float64, len(candles))
+	for i := range atrPct {
+		atrPct[i] = atr[i] / candles[i].Close
+	}
+	pct := percentileRank(atrPct)
+	switch {
+	case math.IsNaN(pct):
+		return "", pct
+	case pct > regimeHighVolPct:
+		return "HIGH", pct
+	case pct < regimeLowVolPct:
+		return "LOW", pct
+	default:
+		return "MEDIUM", pct
+	}
+}
+
+// Trend returns the direction reported as the timeframe trend; a range has no trend
+func (r *MarketRegime) Trend() string {
+	if r.State == RegimeRanging {
+		return "SIDEWAYS"
+	}
+	return r.Direction
+}
+
+// percentileRank returns the share (0-100) of valid values in the series that are at or below
+// the last value, or NaN when the last value is not valid
+func percentileRank(series []float64) float64 {
+	last := Last(series)
+	if math.IsNaN(last) {
+		return math.NaN()
+	}
+	below, total := 0, 0
+	for _, v := range series {
+		if math.IsNaN(v) {
+			continue
+		}
+		total++
+		if v <= last {
+			below++
+		}
+	}
+	return float64(below) / float64(total) * 100
+}
+
+// clamp01 limits v to [0, 1]
+func clamp01(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
+
+// FormatRegimeForAI formats the regime as lines of a timeframe section
+func FormatRegimeForAI(r *MarketRegime) string {
+	return fmt.Sprintf("Trend: %s | Regime: %s | Strength: %d/100 (ADX %.1f, EMA20 slope %+.2f ATR/bar)\n"+
+		"Volatility: %s (ATR percentile %.0f) | BB Width percentile %.0f\n",
+		r.Trend(), r.State, r.Strength, r.ADX, r.Slope, r.Volatility, r.VolatilityPct, r.BandwidthPct)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ForexSessionOffset anchors forex candles to the 22:00 UTC daily open (Sunday 22:00 GMT week open)
+// 4H buckets start at 22:00, 02:00, 06:00, ... UTC and daily/weekly candles at 22:00 UTC the day before
+// Note: DST shifts of the New York close are ignored
+const ForexSessionOffset = -2 * time.Hour
+
+// weekAnchor is the first Monday 00:00 UTC after the Unix epoch (1970-01-05)
+// so weekly buckets start on Monday instead of Thursday
+var weekAnchor = time.Unix(4*24*60*60, 0).UTC()
+
+// resampleBucketStart returns the start of the bucket containing t
+// offset shifts the bucket boundaries relative to UTC midnight (e.g., ForexSessionOffset)
+func resampleBucketStart(t time.Time, interval BinanceInterval, offset time.Duration) time.Time {
+	dur := IntervalDuration(interval)
+	ref := time.Unix(0, 0).UTC()
+	if interval == Interval1w {
+		ref = weekAnchor
+	}
+	ref = ref.Add(offset)
+
+	elapsed := t.Sub(ref)
+	n := elapsed / dur
+	if elapsed < 0 && elapsed%dur != 0 {
+		n-- // floor for times before the reference
+	}
+	return ref.Add(n * dur)
+}
+
+// ResampleCandles aggregates candles into a larger target interval
+// Candles must be sorted by open time and be of a smaller interval than target
+// The last bucket may be partial (still forming), like the live candle on an exchange
+func ResampleCandles(candles []Candlestick, target BinanceInterval, offset time.Duration) []Candlestick {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	dur := IntervalDuration(target)
+	result := make([]Candlestick, 0, len(candles)/2+1)
+
+	var current *Candlestick
+	for _, c := range candles {
+		start := resampleBucketStart(c.OpenTime, target, offset)
+
+		if current == nil || !current.OpenTime.Equal(start) {
+			if current != nil {
+				result = append(result, *current)
+			}
+			current = &Candlestick{
+				OpenTime:      start,
+				Open:          c.Open,
+				High:          c.High,
+				Low:           c.Low,
+				Close:         c.Close,
+				Volume:        c.Volume,
+				CloseTime:     start.Add(dur - time.Millisecond),
+				QuoteVolume:   c.QuoteVolume,
+				Trades:        c.Trades,
+				TakerBuyBase:  c.TakerBuyBase,
+				TakerBuyQuote: c.TakerBuyQuote,
+				Synthetic:     c.Synthetic,
+			}
+			continue
+		}
+
+		if c.High > current.High {
+			current.High = c.High
+		}
+		if c.Low < current.Low {
+			current.Low = c.Low
+		}
+		current.Close = c.Close
+		current.Volume += c.Volume
+		current.QuoteVolume += c.QuoteVolume
+		current.Trades += c.Trades
+		current.TakerBuyBase += c.TakerBuyBase
+		current.TakerBuyQuote += c.TakerBuyQuote
+		current.Synthetic = current.Synthetic || c.Synthetic
+	}
+	result = append(result, *current)
+
+	return result
+}
+
+// ResampleSourceInterval picks the largest supported interval that evenly divides target
+func ResampleSourceInterval(target BinanceInterval, supported []BinanceInterval) (BinanceInterval, bool) {
+	targetDur := IntervalDuration(target)
+
+	var best BinanceInterval
+	var bestDur time.Duration
+	for _, iv := range supported {
+		d := IntervalDuration(iv)
+		if d >= targetDur || targetDur%d != 0 {
+			continue
+		}
+		if d > bestDur {
+			best, bestDur = iv, d
+		}
+	}
+
+	return best, bestDur > 0
+}
+
+// supportsInterval reports whether a provider serves an interval natively
+func supportsInterval(p MarketDataProvider, interval BinanceInterval) bool {
+	for _, iv := range p.SupportedIntervals() {
+		if iv == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchCandlesWithResample fetches candles from a provider, building intervals it
+// does not serve natively by resampling a smaller supported interval
+func FetchCandlesWithResample(ctx context.Context, p MarketDataProvider, symbol string, interval BinanceInterval, limit int, offset time.Duration) ([]Candlestick, error) {
+	if supportsInterval(p, interval) {
+		return p.FetchCandles(ctx, symbol, interval, limit)
+	}
+
+	source, ok := ResampleSourceInterval(interval, p.SupportedIntervals())
+	if !ok {
+		return nil, fmt.Errorf("%s cannot serve or resample interval %s", p.Name(), interval)
+	}
+
+	// Fetch enough source candles for `limit` target candles (+1 for a partial first bucket)
+	ratio := int(IntervalDuration(interval) / IntervalDuration(source))
+	sourceCandles, err := p.FetchCandles(ctx, symbol, source, (limit+1)*ratio)
+	if err != nil {
+		return nil, err
+	}
+
+	candles := ResampleCandles(sourceCandles, interval, offset)
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Smart Money Concepts detection: swing points, market structure (BOS/CHoCH),
+// order blocks, fair value gaps and equal highs/lows, computed over the full history
+
+const (
+	smcSwingLength     = 3    // bars on each side that a swing high/low must exceed
+	smcMinFVGATR       = 0.1  // gaps smaller than this fraction of ATR are noise
+	smcEqualLevelATR   = 0.1  // swings within this fraction of ATR form an equal high/low
+	smcMaxReported     = 3    // zones per kind and direction written to the AI context
+	smcFilledThreshold = 0.99 // fill ratio at which an FVG counts as filled
+)
+
+// SMC directions and zone statuses
+const (
+	SMCBullish = "BULLISH"
+	SMCBearish = "BEARISH"
+
+	ZoneFresh     = "FRESH"     // untouched since it formed
+	ZoneMitigated = "MITIGATED" // price has traded back into the zone
+	ZoneBroken    = "BROKEN"    // price closed through the zone
+	ZoneUnfilled  = "UNFILLED"
+	ZonePartial   = "PARTIAL"
+	ZoneFilled    = "FILLED"
+)
+
+// SwingPoint is a confirmed swing high or low
+type SwingPoint struct {
+	Index     int
+	Time      time.Time
+	Price     float64
+	High      bool
+	RelVolume float64 // swing candle volume / average volume (0 without volume data)
+}
+
+// StructureBreak is a close beyond a swing point
+// Kind is "BOS" when it continues the prior structure and "CHOCH" when it reverses it
+type StructureBreak struct {
+	Kind      string
+	Direction string // BULLISH (swing high broken) or BEARISH (swing low broken)
+	Level     float64
+	SwingTime time.Time
+	Time      time.Time
+	Index     int
+}
+
+// SMCZone is an order block or fair value gap between Bottom and Top
+type SMCZone struct {
+	Direction string
+	Top       float64
+	Bottom    float64
+	Time      time.Time
+	Index     int
+	Status    string
+	Filled    float64 // FVG fill ratio 0..1
+}
+
+// LiquidityPool is a cluster of equal swing highs (EQH) or lows (EQL)
+type LiquidityPool struct {
+	Kind    string // EQH or EQL
+	Price   float64
+	Touches int
+	First   time.Time
+	Last    time.Time
+	Swept   bool
+}
+
+// SMCAnalysis holds everything detected on one timeframe
+type SMCAnalysis struct {
+	Swings      []SwingPoint
+	Structure   []StructureBreak
+	Bias        string // direction of the last structure break (NEUTRAL if none)
+	OrderBlocks []SMCZone
+	FVGs        []SMCZone
+	Liquidity   []LiquidityPool
+}
+
+// DetectSMC runs the full Smart Money Concepts detection on candles (oldest first)
+// Returns nil when there are too few candles to confirm a swing
+func DetectSMC(candles []Candlestick) *SMCAnalysis {
+	if len(candles) < 2*smcSwingLength+1 {
+		return nil
+	}
+
+	atr := ATR(candles, atrPeriod)
+	refATR := lastOrZero(atr)
+	if refATR == 0 {
+		refATR = averageRange(candles)
+	}
+
+	a := &SMCAnalysis{Swings: detectSwings(candles, smcSwingLength), Bias: "NEUTRAL"}
+	a.Structure, a.OrderBlocks = detectStructure(candles, a.Swings, smcSwingLength)
+	if len(a.Structure) > 0 {
+		a.Bias = a.Structure[len(a.Structure)-1].Direction
+	}
+	a.FVGs = detectFVGs(candles, atr)
+	a.Liquidity = detectLiquidityPools(candles, a.Swings, refATR*smcEqualLevelATR)
+	return a
+}
+
+// averageRange is the mean high-low range, used when the history is too short for ATR
+func averageRange(candles []Candlestick) float64 {
+	sum := 0.0
+	for _, c := range candles {
+		sum += c.High - c.Low
+	}
+	return sum / float64(len(candles))
+}
+
+// detectSwings finds fractal swing points: a high (low) strictly above (below) the
+// length bars before it and not exceeded by the length bars after it
+func detectSwings(candles []Candlestick, length int) []SwingPoint {
+	avgVolume := 0.0
+	for _, c := range candles {
+		avgVolume += c.Volume / float64(len(candles))
+	}
+	relVolume := func(i int) float64 {
+		if avgVolume == 0 {
+			return 0
+		}
+		return candles[i].Volume / avgVolume
+	}
+
+	var swings []SwingPoint
+	for i := length; i < len(candles)-length; i++ {
+		isHigh, isLow := true, true
+		for j := i - length; j <= i+length && (isHigh || isLow); j++ {
+			if j == i {
+				continue
+			}
+			if j < i {
+				isHigh = isHigh && candles[j].High < candles[i].High
+				isLow = isLow && candles[j].Low > candles[i].Low
+			} else {
+				isHigh = isHigh && candles[j].High <= candles[i].High
+				isLow = isLow && candles[j].Low >= candles[i].Low
+			}
+		}
+		if isHigh {
+			swings = append(swings, SwingPoint{Index: i, Time: candles[i].OpenTime, Price: candles[i].High, High: true, RelVolume: relVolume(i)})
+		}
+		if isLow {
+			swings = append(swings, SwingPoint{Index: i, Time: candles[i].OpenTime, Price: candles[i].Low, RelVolume: relVolume(i)})
+		}
+	}
+	return swings
+}
+
+// detectStructure walks the candles and records a break each time a close crosses the
+// latest confirmed, unbroken swing; each break also produces the order block behind it
+func detectStructure(candles []Candlestick, swings []SwingPoint, length int) ([]StructureBreak, []SMCZone) {
+	var breaks []StructureBreak
+	var blocks []SMCZone
+	var high, low *SwingPoint
+	trend := ""
+	next := 0
+
+	for i, c := range candles {
+		// A swing is only known once length bars have closed after it
+		for next < len(swings) && swings[next].Index+length <= i {
+			s := swings[next]
+			if s.High {
+				high = &s
+			} else {
+				low = &s
+			}
+			next++
+		}
+
+		if high != nil && c.Close > high.Price {
+			breaks = append(breaks, newStructureBreak(trend, SMCBullish, *high, c, i))
+			blocks = append(blocks, orderBlockBefore(candles, high.Index, i, SMCBullish))
+			trend = SMCBullish
+			high = nil
+		}
+		if low != nil && c.Close < low.Price {
+			breaks = append(breaks, newStructureBreak(trend, SMCBearish, *low, c, i))
+			blocks = append(blocks, orderBlockBefore(candles, low.Index, i, SMCBearish))
+			trend = SMCBearish
+			low = nil
+		}
+	}
+
+	for i := range blocks {
+		updateOrderBlockStatus(&blocks[i], candles)
+	}
+	return breaks, blocks
+}
+
+// newStructureBreak labels a break as BOS or CHOCH relative to the prior trend
+func newStructureBreak(trend, direction string, swing SwingPoint, c Candlestick, index int) StructureBreak {
+	kind := "BOS"
+	if trend != "" && trend != direction {
+		kind = "CHOCH"
+	}
+	return StructureBreak{
+		Kind:      kind,
+		Direction: direction,
+		Level:     swing.Price,
+		SwingTime: swing.Time,
+		Time:      c.OpenTime,
+		Index:     index,
+	}
+}
+
+// orderBlockBefore returns the last opposite-colored candle between the swing and the
+// break (the origin of the displacement); falls back to the extreme candle of the leg
+func orderBlockBefore(candles []Candlestick, from, to int, direction string) SMCZone {
+	pick := -1
+	for j := to - 1; j >= from; j-- {
+		c := candles[j]
+		if (direction == SMCBullish && c.Close < c.Open) || (direction == SMCBearish && c.Close > c.Open) {
+			pick = j
+			break
+		}
+	}
+	if pick < 0 {
+		pick = from
+		for j := from; j < to; j++ {
+			if (direction == SMCBullish && candles[j].Low < candles[pick].Low) ||
+				(direction == SMCBearish && candles[j].High > candles[pick].High) {
+				pick = j
+			}
+		}
+	}
+
+	c := candles[pick]
+	return SMCZone{Direction: direction, Top: c.High, Bottom: c.Low, Time: c.OpenTime, Index: to}
+}
+
+// updateOrderBlockStatus checks the candles after the break for a retest or a close through the block
+func updateOrderBlockStatus(ob *SMCZone, candles []Candlestick) {
+	ob.Status = ZoneFresh
+	for _, c := range candles[ob.Index+1:] {
+		if ob.Direction == SMCBullish {
+			if c.Close < ob.Bottom {
+				ob.Status = ZoneBroken
+				return
+			}
+			if c.Low <= ob.Top {
+				ob.Status = ZoneMitigated
+			}
+		} else {
+			if c.Close > ob.Top {
+				ob.Status = ZoneBroken
+				return
+			}
+			if c.High >= ob.Bottom {
+				ob.Status = ZoneMitigated
+			}
+		}
+	}
+}
+
+// detectFVGs finds three-candle imbalances (candle 3 not overlapping candle 1)
+// and how much of each gap later candles have filled
+func detectFVGs(candles []Candlestick, atr []float64) []SMCZone {
+	var gaps []SMCZone
+	for i := 2; i < len(candles); i++ {
+		first, third := candles[i-2], candles[i]
+		var gap SMCZone
+		switch {
+		case third.Low > first.High:
+			gap = SMCZone{Direction: SMCBullish, Top: third.Low, Bottom: first.High}
+		case third.High < first.Low:
+			gap = SMCZone{Direction: SMCBearish, Top: first.Low, Bottom: third.High}
+		default:
+			continue
+		}
+		if !math.IsNaN(atr[i]) && gap.Top-gap.Bottom < atr[i]*smcMinFVGATR {
+			continue
+		}
+		gap.Time = candles[i-1].OpenTime
+		gap.Index = i
+		updateFVGFill(&gap, candles)
+		gaps = append(gaps, gap)
+	}
+	return gaps
+}
+
+// updateFVGFill measures the deepest retrace into the gap after it formed
+func updateFVGFill(gap *SMCZone, candles []Candlestick) {
+	size := gap.Top - gap.Bottom
+	for _, c := range candles[gap.Index+1:] {
+		depth := 0.0
+		if gap.Direction == SMCBullish {
+			depth = gap.Top - c.Low
+		} else {
+			depth = c.High - gap.Bottom
+		}
+		gap.Filled = math.Max(gap.Filled, math.Min(depth/size, 1))
+	}
+
+	switch {
+	case gap.Filled >= smcFilledThreshold:
+		gap.Status = ZoneFilled
+	case gap.Filled > 0:
+		gap.Status = ZonePartial
+	default:
+		gap.Status = ZoneUnfilled
+	}
+}
+
+// detectLiquidityPools groups swing highs (lows) within tolerance of each other
+// A pool is swept once a later candle trades beyond its most extreme touch
+func detectLiquidityPools(candles []Candlestick, swings []SwingPoint, tolerance float64) []LiquidityPool {
+	var pools []LiquidityPool
+	for _, high := range []bool{true, false} {
+		var points []SwingPoint
+		for _, s := range swings {
+			if s.High == high {
+				points = append(points, s)
+			}
+		}
+		sort.Slice(points, func(i, j int) bool { return points[i].Price < points[j].Price })
+
+		for start := 0; start < len(points); {
+			end := start + 1
+			for end < len(points) && points[end].Price-points[end-1].Price <= tolerance {
+				end++
+			}
+			if end-start >= 2 {
+				pools = append(pools, newLiquidityPool(candles, points[start:end], high))
+			}
+			start = end
+		}
+	}
+	return pools
+}
+
+// newLiquidityPool summarizes a cluster of equal swing points
+func newLiquidityPool(candles []Candlestick, cluster []SwingPoint, high bool) LiquidityPool {
+	pool := LiquidityPool{Kind: "EQL", Touches: len(cluster)}
+	if high {
+		pool.Kind = "EQH"
+	}
+
+	extreme := cluster[0].Price
+	firstIndex, lastIndex := cluster[0].Index, cluster[0].Index
+	sum := 0.0
+	for _, s := range cluster {
+		sum += s.Price
+		if (high && s.Price > extreme) || (!high && s.Price < extreme) {
+			extreme = s.Price
+		}
+		firstIndex = min(firstIndex, s.Index)
+		lastIndex = max(lastIndex, s.Index)
+	}
+	pool.Price = sum / float64(len(cluster))
+	pool.First = candles[firstIndex].OpenTime
+	pool.Last = candles[lastIndex].OpenTime
+
+	for _, c := range candles[lastIndex+1:] {
+		if (high && c.High > extreme) || (!high && c.Low < extreme) {
+			pool.Swept = true
+			break
+		}
+	}
+	return pool
+}
+
+// ActiveOrderBlocks returns the unbroken order blocks of a direction, most recent first
+func (a *SMCAnalysis) ActiveOrderBlocks(direction string) []SMCZone {
+	return recentZones(a.OrderBlocks, direction, func(z SMCZone) bool { return z.Status != ZoneBroken })
+}
+
+// OpenFVGs returns the unfilled or partially filled gaps of a direction, most recent first
+func (a *SMCAnalysis) OpenFVGs(direction string) []SMCZone {
+	return recentZones(a.FVGs, direction, func(z SMCZone) bool { return z.Status != ZoneFilled })
+}
+
+// UnsweptLiquidity returns the pools not yet swept, most recently touched first, capped at
+// 2 x smcMaxReported for EQH and EQL together (a.Liquidity is ordered by kind and price)
+func (a *SMCAnalysis) UnsweptLiquidity() []LiquidityPool {
+	var out []LiquidityPool
+	for _, lp := range a.Liquidity {
+		if !lp.Swept {
+			out = append(out, lp)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Last.After(out[j].Last) })
+	if len(out) > 2*smcMaxReported {
+		out = out[:2*smcMaxReported]
+	}
+	return out
+}
+
+// Since returns a copy limited to a chart window starting at from: swings and structure breaks
+// inside the window, and zones and pools that formed inside it or are still open there
+func (a *SMCAnalysis) Since(from time.Time) *SMCAnalysis {
+	out := &SMCAnalysis{Bias: a.Bias}
+	for _, s := range a.Swings {
+		if !s.Time.Before(from) {
+			out.Swings = append(out.Swings, s)
+		}
+	}
+	for _, b := range a.Structure {
+		if !b.Time.Before(from) {
+			out.Structure = append(out.Structure, b)
+		}
+	}
+	for _, z := range a.OrderBlocks {
+		if !z.Time.Before(from) || z.Status != ZoneBroken {
+			out.OrderBlocks = append(out.OrderBlocks, z)
+		}
+	}
+	for _, z := range a.FVGs {
+		if !z.Time.Before(from) || z.Status != ZoneFilled {
+			out.FVGs = append(out.FVGs, z)
+		}
+	}
+	for _, lp := range a.Liquidity {
+		if !lp.Last.Before(from) || !lp.Swept {
+			out.Liquidity = append(out.Liquidity, lp)
+		}
+	}
+	return out
+}
+
+// recentZones filters zones by direction and predicate, newest first, capped at smcMaxReported
+func recentZones(zones []SMCZone, direction string, keep func(SMCZone) bool) []SMCZone {
+	var out []SMCZone
+	for i := len(zones) - 1; i >= 0 && len(out) < smcMaxReported; i-- {
+		if zones[i].Direction == direction && keep(zones[i]) {
+			out = append(out, zones[i])
+		}
+	}
+	return out
+}
+
+// FormatSMCForAI formats the detected structure and zones as lines of a timeframe section
+func FormatSMCForAI(a *SMCAnalysis, prec InstrumentPrecision) string {
+	var sb strings.Builder
+	p := prec.Format
+	when := func(t time.Time) string { return t.UTC().Format("01-02 15:04") }
+
+	sb.WriteString(fmt.Sprintf("SMC Structure Bias: %s\n", a.Bias))
+	if n := len(a.Structure); n > 0 {
+		var parts []string
+		for _, b := range a.Structure[max(0, n-smcMaxReported):] {
+			parts = append(parts, fmt.Sprintf("%s %s @ %s (%s)", b.Direction, b.Kind, p(b.Level), when(b.Time)))
+		}
+		sb.WriteString(fmt.Sprintf("Recent Breaks: %s\n", strings.Join(parts, " | ")))
+	}
+
+	zoneLine := func(label string, zones []SMCZone, fill bool) {
+		if len(zones) == 0 {
+			return
+		}
+		var parts []string
+		for _, z := range zones {
+			status := z.Status
+			if fill && z.Status == ZonePartial {
+				status = fmt.Sprintf("%s %.0f%%", status, z.Filled*100)
+			}
+			parts = append(parts, fmt.Sprintf("%s-%s %s (%s)", p(z.Bottom), p(z.Top), status, when(z.Time)))
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s\n", label, strings.Join(parts, " | ")))
+	}
+	zoneLine("Bullish OB", a.ActiveOrderBlocks(SMCBullish), false)
+	zoneLine("Bearish OB", a.ActiveOrderBlocks(SMCBearish), false)
+	zoneLine("Bullish FVG", a.OpenFVGs(SMCBullish), true)
+	zoneLine("Bearish FVG", a.OpenFVGs(SMCBearish), true)
+
+	var pools []string
+	for _, lp := range a.UnsweptLiquidity() {
+		pools = append(pools, fmt.Sprintf("%s %s x%d", lp.Kind, p(lp.Price), lp.Touches))
+	}
+	if len(pools) > 0 {
+		sb.WriteString(fmt.Sprintf("Liquidity (unswept): %s\n", strings.Join(pools, " | ")))
+	}
+
+	return sb.String()
+}
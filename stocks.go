@@ -0,0 +1,450 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StockExchange describes the regular trading session of an exchange
+type StockExchange struct {
+	Code     string // Short code (e.g., "US", "IDX")
+	Name     string
+	Timezone string        // IANA timezone of the session hours
+	Open     time.Duration // Session open since local midnight
+	Close    time.Duration // Session close since local midnight
+	Currency string
+	TVPrefix string // TradingView exchange prefix (empty = let TradingView resolve)
+}
+
+// Exchanges served through Yahoo Finance, keyed by Yahoo ticker suffix
+var (
+	ExchangeUS   = StockExchange{Code: "US", Name: "NYSE/NASDAQ", Timezone: "America/New_York", Open: 9*time.Hour + 30*time.Minute, Close: 16 * time.Hour, Currency: "USD"}
+	ExchangeIDX  = StockExchange{Code: "IDX", Name: "Bursa Efek Indonesia", Timezone: "Asia/Jakarta", Open: 9 * time.Hour, Close: 16 * time.Hour, Currency: "IDR", TVPrefix: "IDX"}
+	ExchangeLSE  = StockExchange{Code: "LSE", Name: "London Stock Exchange", Timezone: "Europe/London", Open: 8 * time.Hour, Close: 16*time.Hour + 30*time.Minute, Currency: "GBP", TVPrefix: "LSE"}
+	ExchangeTSE  = StockExchange{Code: "TSE", Name: "Tokyo Stock Exchange", Timezone: "Asia/Tokyo", Open: 9 * time.Hour, Close: 15*time.Hour + 30*time.Minute, Currency: "JPY", TVPrefix: "TSE"}
+	ExchangeHKEX = StockExchange{Code: "HKEX", Name: "Hong Kong Exchange", Timezone: "Asia/Hong_Kong", Open: 9*time.Hour + 30*time.Minute, Close: 16 * time.Hour, Currency: "HKD", TVPrefix: "HKEX"}
+	ExchangeSGX  = StockExchange{Code: "SGX", Name: "Singapore Exchange", Timezone: "Asia/Singapore", Open: 9 * time.Hour, Close: 17 * time.Hour, Currency: "SGD", TVPrefix: "SGX"}
+	// CME Globex trades nearly 24h on weekdays (17:00-16:00 Chicago)
+	ExchangeCME = StockExchange{Code: "CME", Name: "CME Globex (Futures)", Timezone: "America/Chicago", Open: 0, Close: 24 * time.Hour, Currency: "USD"}
+)
+
+// stockSuffixExchanges maps Yahoo ticker suffixes to exchanges
+var stockSuffixExchanges = map[string]StockExchange{
+	".JK": ExchangeIDX,
+	".L":  ExchangeLSE,
+	".T":  ExchangeTSE,
+	".HK": ExchangeHKEX,
+	".SI": ExchangeSGX,
+}
+
+// stockIndexExchanges maps index tickers to the exchange whose hours they follow
+var stockIndexExchanges = map[string]StockExchange{
+	"^GSPC": ExchangeUS,
+	"^DJI":  ExchangeUS,
+	"^IXIC": ExchangeUS,
+	"^NDX":  ExchangeUS,
+	"^RUT":  ExchangeUS,
+	"^VIX":  ExchangeUS,
+	"^JKSE": ExchangeIDX,
+	"^N225": ExchangeTSE,
+	"^HSI":  ExchangeHKEX,
+	"^FTSE": ExchangeLSE,
+	"^STI":  ExchangeSGX,
+}
+
+// StockAlias is a friendly name for a Yahoo ticker
+type StockAlias struct {
+	Symbol      string
+	DisplayName string
+}
+
+// stockAliases lets users type common names instead of Yahoo tickers
+var stockAliases = map[string]StockAlias{
+	"SPX":    {Symbol: "^GSPC", DisplayName: "US 500 (SPX)"},
+	"SP500":  {Symbol: "^GSPC", DisplayName: "US 500 (SPX)"},
+	"DJI":    {Symbol: "^DJI", DisplayName: "Dow Jones"},
+	"DOW":    {Symbol: "^DJI", DisplayName: "Dow Jones"},
+	"NASDAQ": {Symbol: "^IXIC", DisplayName: "Nasdaq Composite"},
+	"NDX":    {Symbol: "^NDX", DisplayName: "Nasdaq 100"},
+	"VIX":    {Symbol: "^VIX", DisplayName: "CBOE Volatility Index"},
+	"IHSG":   {Symbol: "^JKSE", DisplayName: "IHSG (Jakarta Composite)"},
+	"JCI":    {Symbol: "^JKSE", DisplayName: "IHSG (Jakarta Composite)"},
+	"NIKKEI": {Symbol: "^N225", DisplayName: "Nikkei 225"},
+	"HSI":    {Symbol: "^HSI", DisplayName: "Hang Seng"},
+	"FTSE":   {Symbol: "^FTSE", DisplayName: "FTSE 100"},
+	"WTI":    {Symbol: "CL=F", DisplayName: "Crude Oil WTI"},
+	"OIL":    {Symbol: "CL=F", DisplayName: "Crude Oil WTI"},
+	"BRENT":  {Symbol: "BZ=F", DisplayName: "Brent Crude"},
+	"NATGAS": {Symbol: "NG=F", DisplayName: "Natural Gas"},
+	"GOLD":   {Symbol: "GC=F", DisplayName: "Gold Futures"},
+	"SILVER": {Symbol: "SI=F", DisplayName: "Silver Futures"},
+	"COPPER": {Symbol: "HG=F", DisplayName: "Copper Futures"},
+}
+
+// tradingViewSymbols maps Yahoo tickers whose TradingView symbol differs
+var tradingViewSymbols = map[string]string{
+	"^GSPC": "SP:SPX",
+	"^DJI":  "DJ:DJI",
+	"^IXIC": "NASDAQ:IXIC",
+	"^NDX":  "NASDAQ:NDX",
+	"^RUT":  "TVC:RUT",
+	"^VIX":  "TVC:VIX",
+	"^JKSE": "IDX:COMPOSITE",
+	"^N225": "TVC:NI225",
+	"^HSI":  "TVC:HSI",
+	"^FTSE": "TVC:UKX",
+	"^STI":  "TVC:STI",
+	"CL=F":  "NYMEX:CL1!",
+	"BZ=F":  "NYMEX:BB1!",
+	"NG=F":  "NYMEX:NG1!",
+	"GC=F":  "COMEX:GC1!",
+	"SI=F":  "COMEX:SI1!",
+	"HG=F":  "COMEX:HG1!",
+	"ES=F":  "CME_MINI:ES1!",
+	"NQ=F":  "CME_MINI:NQ1!",
+	"YM=F":  "CBOT_MINI:YM1!",
+	"RTY=F": "CME_MINI:RTY1!",
+}
+
+// futuresTickSizes are the minimum price increments of common futures contracts
+var futuresTickSizes = map[string]float64{
+	"CL=F":  0.01,
+	"BZ=F":  0.01,
+	"NG=F":  0.001,
+	"GC=F":  0.1,
+	"SI=F":  0.005,
+	"HG=F":  0.0005,
+	"ES=F":  0.25,
+	"NQ=F":  0.25,
+	"YM=F":  1,
+	"RTY=F": 0.1,
+}
+
+// stockTickerPattern accepts Yahoo tickers such as AAPL, BRK-B, BBCA.JK, ^GSPC and CL=F
+var stockTickerPattern = regexp.MustCompile(`^\^?[A-Z0-9][A-Z0-9.\-]{0,14}(=F)?$`)
+
+// NormalizeStockSymbol converts user input to a Yahoo stock, index or futures ticker
+func NormalizeStockSymbol(input string) (string, string, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(input))
+
+	if alias, ok := stockAliases[symbol]; ok {
+		return alias.Symbol, alias.DisplayName, nil
+	}
+	if strings.HasSuffix(symbol, "=X") {
+		return "", "", fmt.Errorf("%s is a forex pair, use /fxsc, /fxsw or /fxint", symbol)
+	}
+	if !stockTickerPattern.MatchString(symbol) {
+		return "", "", fmt.Errorf("invalid ticker: %s (use format like AAPL, BBCA.JK, ^GSPC or CL=F)", symbol)
+	}
+
+	for _, alias := range stockAliases {
+		if alias.Symbol == symbol {
+			return symbol, alias.DisplayName, nil
+		}
+	}
+	return symbol, symbol, nil
+}
+
+// StockExchangeFor returns the exchange whose session hours apply to a ticker
+func StockExchangeFor(symbol string) StockExchange {
+	if strings.HasSuffix(symbol, "=F") {
+		return ExchangeCME
+	}
+	if exch, ok := stockIndexExchanges[symbol]; ok {
+		return exch
+	}
+	if i := strings.LastIndex(symbol, "."); i > 0 {
+		if exch, ok := stockSuffixExchanges[symbol[i:]]; ok {
+			return exch
+		}
+	}
+	return ExchangeUS
+}
+
+// location loads the exchange timezone (UTC when tzdata is unavailable)
+func (e StockExchange) location() *time.Location {
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// SessionLength returns the length of the regular trading session
+func (e StockExchange) SessionLength() time.Duration {
+	return e.Close - e.Open
+}
+
+// IsOpen reports whether the regular session is open at t (weekends closed, holidays ignored)
+func (e StockExchange) IsOpen(t time.Time) bool {
+	local := t.In(e.location())
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	sinceMidnight := local.Sub(time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location()))
+	return sinceMidnight >= e.Open && sinceMidnight < e.Close
+}
+
+// SessionHours returns the session hours in local exchange time (e.g., "09:30-16:00 America/New_York")
+func (e StockExchange) SessionHours() string {
+	if e.SessionLength() >= 24*time.Hour {
+		return fmt.Sprintf("~23 jam/hari, Senin-Jumat (%s)", e.Timezone)
+	}
+	format := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%s-%s %s, Senin-Jumat", format(e.Open), format(e.Close), e.Timezone)
+}
+
+// GetStockTimeframesForMode returns timeframes for a trading mode, adjusted to the session length
+// 4H candles are skipped for cash sessions shorter than 8 hours, where every day would end
+// in a partial 4H bucket
+func GetStockTimeframesForMode(exch StockExchange, mode TradingMode) []BinanceInterval {
+	var timeframes []BinanceInterval
+	switch mode {
+	case TradingModeScalping:
+		timeframes = []BinanceInterval{Interval5m, Interval15m, Interval1h, Interval4h, Interval1d}
+	case TradingModeSwing:
+		timeframes = []BinanceInterval{Interval1h, Interval4h, Interval1d, Interval1w}
+	case TradingModeIntraday:
+		timeframes = []BinanceInterval{Interval5m, Interval15m, Interval1h, Interval4h, Interval1d}
+	default:
+		timeframes = []BinanceInterval{Interval15m, Interval1h, Interval4h, Interval1d, Interval1w}
+	}
+
+	if exch.SessionLength() >= 8*time.Hour {
+		return timeframes
+	}
+	filtered := timeframes[:0]
+	for _, tf := range timeframes {
+		if tf != Interval4h {
+			filtered = append(filtered, tf)
+		}
+	}
+	return filtered
+}
+
+// TradingViewSymbol maps a Yahoo ticker to a TradingView symbol
+func TradingViewSymbol(symbol string) string {
+	if tv, ok := tradingViewSymbols[symbol]; ok {
+		return tv
+	}
+	exch := StockExchangeFor(symbol)
+	ticker := symbol
+	if i := strings.LastIndex(symbol, "."); i > 0 {
+		ticker = symbol[:i]
+	}
+	ticker = strings.TrimSuffix(strings.ReplaceAll(ticker, "-", "."), "=F")
+	if exch.TVPrefix == "" {
+		return ticker
+	}
+	return exch.TVPrefix + ":" + ticker
+}
+
+// StockPrecision returns the price precision of a stock, index or futures ticker
+func StockPrecision(symbol string) InstrumentPrecision {
+	if tick, ok := futuresTickSizes[symbol]; ok {
+		return InstrumentPrecision{TickSize: tick, Decimals: decimalsForStep(tick)}
+	}
+	if strings.HasPrefix(symbol, "^") {
+		return InstrumentPrecision{TickSize: 0.01, Decimals: 2}
+	}
+	switch StockExchangeFor(symbol).Code {
+	case "IDX":
+		return InstrumentPrecision{TickSize: 1, Decimals: 0}
+	case "TSE":
+		return InstrumentPrecision{TickSize: 0.1, Decimals: 1}
+	case "HKEX":
+		return InstrumentPrecision{TickSize: 0.001, Decimals: 3}
+	default:
+		return InstrumentPrecision{TickSize: 0.01, Decimals: 2}
+	}
+}
+
+// StockProvider serves stocks, indices and futures from Yahoo Finance
+// It shares YahooProvider's fetching and only differs in symbol handling
+type StockProvider struct {
+	YahooProvider
+}
+
+// Market returns MarketStocks
+func (StockProvider) Market() Market { return MarketStocks }
+
+// NormalizeSymbol converts input to a Yahoo ticker via NormalizeStockSymbol
+func (StockProvider) NormalizeSymbol(input string) (string, string, error) {
+	return NormalizeStockSymbol(input)
+}
+
+// Precision returns the precision via StockPrecision
+func (StockProvider) Precision(symbol string) InstrumentPrecision {
+	return StockPrecision(symbol)
+}
+
+// FormatStockDataForAI formats stock multi-timeframe data for AI analysis
+func FormatStockDataForAI(symbol, displayName string, prec InstrumentPrecision, summaries []CandleDataSummary, mode TradingMode) string {
+	var sb strings.Builder
+	exch := StockExchangeFor(symbol)
+
+	session := "CLOSED"
+	if exch.IsOpen(time.Now()) {
+		session = "OPEN"
+	}
+
+	sb.WriteString("=== STOCK/INDEX MULTI-TIMEFRAME DATA ANALYSIS ===\n")
+	sb.WriteString(fmt.Sprintf("Symbol: %s (%s)\n", displayName, symbol))
+	sb.WriteString(fmt.Sprintf("Exchange: %s | Currency: %s\n", exch.Name, exch.Currency))
+	sb.WriteString(fmt.Sprintf("Session: %s | Hours: %s\n", session, exch.SessionHours()))
+	sb.WriteString(fmt.Sprintf("Analysis Mode: %s\n", strings.ToUpper(string(mode))))
+	sb.WriteString("Data Source: Yahoo Finance\n\n")
+
+	for _, s := range summaries {
+		writeTimeframeSection(&sb, s, prec)
+	}
+
+	sb.WriteString(FormatSupportResistanceForAI(DetectSupportResistance(summaries), prec))
+
+	return sb.String()
+}
+
+// GenerateStockAnalysisPrompt creates a specialized prompt for stocks, indices and futures
+func GenerateStockAnalysisPrompt(mode TradingMode, symbol, displayName string, prec InstrumentPrecision, dataContext string) string {
+	exch := StockExchangeFor(symbol)
+	baseRole := ""
+	strategy := ""
+
+	switch mode {
+	case TradingModeScalping:
+		baseRole = `ROLE: Kamu adalah "Antigravity Equity Scalper", trader saham agresif yang memanfaatkan momentum di jam pembukaan dan penutupan bursa.`
+		strategy = `METODE SCALPING SAHAM:
+- Fokus pada 1 jam pertama dan terakhir sesi (volume tertinggi).
+- Perhatikan gap pembukaan dan apakah gap tersebut di-fill.
+- Entry hanya saat volume di atas rata-rata.
+- Risk Reward Ratio minimal 1:2 dengan stoploss ketat.`
+	case TradingModeSwing:
+		baseRole = `ROLE: Kamu adalah "Antigravity Equity Swing Master", investor-trader sabar yang menunggu setup daily/weekly.`
+		strategy = `METODE SWING SAHAM:
+- Analisa trend daily/weekly dan posisi terhadap MA50.
+- Perhatikan jadwal earnings/laporan keuangan, dividen dan corporate action.
+- Entry di pullback ke area demand yang kuat.
+- Risk Reward Ratio minimal 1:3.`
+	case TradingModeIntraday:
+		baseRole = `ROLE: Kamu adalah "Antigravity Equity Intraday Pro", trader harian yang menutup semua posisi sebelum bursa tutup.`
+		strategy = `METODE INTRADAY SAHAM:
+- Gunakan high/low sesi sebelumnya sebagai level kunci.
+- Gunakan SMC untuk identifikasi order blocks dan FVG.
+- Close semua posisi sebelum sesi berakhir (hindari risiko gap overnight).`
+	default:
+		baseRole = `ROLE: Kamu adalah "Antigravity Equity Analyst", AI analis saham, indeks dan komoditas profesional.`
+		strategy = `METODE STANDARD:
+- Gunakan Smart Money Concept (SMC) + Supply Demand.
+- Validasi dengan fundamental (earnings, sektor, makro).
+- Cari confluence antara teknikal dan fundamental.`
+	}
+
+	marketNotes := "- Perhatikan risiko gap karena bursa tidak buka 24 jam."
+	switch exch.Code {
+	case "IDX":
+		marketNotes = `- Bursa Efek Indonesia: 1 lot = 100 lembar, harga dalam Rupiah (fraksi harga bertingkat).
+- Perhatikan batas Auto Rejection (ARA/ARB) dan aliran dana asing (foreign flow).
+- Perhatikan risiko gap karena bursa tidak buka 24 jam.`
+	case "CME":
+		marketNotes = `- Kontrak futures: perhatikan tanggal rollover/expiry dan laporan inventori/makro terkait.
+- Pasar hampir 24 jam, likuiditas terendah di luar jam sesi Amerika.`
+	}
+
+	return fmt.Sprintf(`%s
+
+DATA MARKET REAL-TIME (Yahoo Finance):
+%s
+
+%s
+
+CONTEXT MARKET:
+- Symbol: %s (%s)
+- Exchange: %s (%s)
+- Trading Hours: %s
+%s
+
+TUGAS ANALISIS TOP-DOWN:
+
+LANGKAH 1: EXTERNAL DATA VALIDATION
+- Cari berita dan sentimen terbaru untuk %s menggunakan Google Search.
+- Cek jadwal earnings, dividen, atau rilis data makro yang relevan.
+
+LANGKAH 2: MULTI-TIMEFRAME ANALYSIS
+- Analisa dari timeframe TERBESAR ke TERKECIL
+%s
+- Identifikasi: Trend utama di HTF (Daily/Weekly)
+- Cari entry presisi di LTF (1H/15m)
+- Jika Session CLOSED, rencanakan entry untuk sesi berikutnya
+
+LANGKAH 3: SMART MONEY ANALYSIS
+%s
+- Order Blocks (OB) dan area Supply/Demand
+- Fair Value Gaps (FVG) / Gap harga antar sesi
+- Break of Structure (BOS) / Change of Character (ChoCh)
+- Konfirmasi dengan volume
+
+LANGKAH 4: ENTRY SETUP
+- Entry Point yang optimal (harga spesifik dengan %s)
+- Stoploss (behind structure / invalidation level)
+- Take Profit 1, 2, 3 (berdasarkan structure targets)
+- Risk:Reward Ratio
+
+--------------------------------------------------------
+CRITICAL RULE:
+1. GUNAKAN FORMAT HTML (Telegram Compatible).
+2. Escape karakter < > & di dalam teks biasa.
+3. GUNAKAN Code Block "diff" untuk warna merah/hijau.
+4. BERIKAN HARGA SPESIFIK untuk Entry, SL, TP (dengan %s).
+--------------------------------------------------------
+
+OUTPUT FORMAT (STRICT HTML):
+
+<b>🛸 ANTIGRAVITY EQUITY</b>
+<code>%s</code> • <code>%s</code>
+
+<b>⚙️ STRATEGY MODE: %s</b>
+
+<blockquote>💡 <i>"[Quote insight singkat tentang setup ini]"</i></blockquote>
+
+<b>📊 MARKET STRUCTURE</b>
+HTF Trend (1W/1D): <b>[BULLISH/BEARISH]</b>
+LTF Trend (1H/15m): <b>[BULLISH/BEARISH]</b>
+Key Support: [level harga]
+Key Resistance: [level harga]
+Demand Zone: [harga bawah] - [harga atas]
+Supply Zone: [harga bawah] - [harga atas]
+Session: [OPEN/CLOSED]
+
+<b>💎 SIGNAL CARD</b>
+<pre><code class="language-diff">
+[Gunakan tanda + untuk HIJAU (Buy/TP/Positif)]
+[Gunakan tanda - untuk MERAH (Sell/SL/Negatif)]
+
++ ACTION:  [BUY/SELL/WAIT]
++ ENTRY:   [harga entry spesifik]
+- SL:      [harga stoploss]
++ TP 1:    [target 1]
++ TP 2:    [target 2]
++ TP 3:    [target 3]
++ R:R:     [rasio risk reward]
+</code></pre>
+
+<b>📈 CONFIDENCE: [XX]%%</b>
+
+<b>📝 ANALYSIS BRIEF</b>
+[Jelaskan alasan teknikal dan fundamental secara padat - max 2 paragraf]
+
+<b>⚠️ RISK NOTES</b>
+- Position Size: Max [X]%% dari portfolio
+- [Event risk: earnings/dividen/rilis data]
+- [Kondisi invalidasi setup]
+
+---
+<i>Generated by Antigravity AI • Equity Analysis • Yahoo Finance Data</i>
+`, baseRole, dataContext, strategy, displayName, symbol, exch.Name, exch.Currency, exch.SessionHours(), marketNotes,
+		displayName, promptRegimeRule, promptDataRules, prec.Describe(), prec.Describe(), displayName, exch.Code, getTradingModeName(mode))
+}
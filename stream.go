@@ -0,0 +1,611 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Binance WebSocket combined stream endpoints (with fallback)
+const (
+	BinanceGlobalStreamURL = "wss://stream.binance.com:9443/stream"
+	BinanceUSStreamURL     = "wss://stream.binance.us:9443/stream"
+)
+
+// binanceStreamURLs is the list of stream endpoints to try on (re)connect
+var binanceStreamURLs = []string{
+	BinanceGlobalStreamURL,
+	BinanceUSStreamURL,
+}
+
+// Reconnect backoff bounds
+const (
+	streamMinBackoff = 1 * time.Second
+	streamMaxBackoff = 60 * time.Second
+)
+
+// Connection liveness: every message, ping and pong extends the read deadline and a ping is
+// sent every streamPingInterval, so a half-open connection fails the read and gets redialed
+const (
+	streamReadTimeout  = 60 * time.Second
+	streamPingInterval = 20 * time.Second
+	streamWriteTimeout = 10 * time.Second
+)
+
+// streamMaxTickerAge is how old a streamed ticker may be before LastPrice ignores it
+// (mini tickers are pushed every second while the connection is healthy)
+const streamMaxTickerAge = 5 * time.Second
+
+// Mini ticker subscriptions nobody asked a price from in streamTickerIdle are dropped, keeping
+// the connection far below Binance's 1024 streams and the resubscribe message small
+const (
+	streamTickerIdle   = 15 * time.Minute
+	streamIdleCheckGap = time.Minute
+)
+
+// MiniTicker is a 24h rolling window ticker from the @miniTicker stream
+type MiniTicker struct {
+	Symbol      string
+	EventTime   time.Time
+	Close       float64
+	Open        float64
+	High        float64
+	Low         float64
+	Volume      float64
+	QuoteVolume float64
+}
+
+// KlineEvent is a candle update from the @kline_<interval> stream
+type KlineEvent struct {
+	Symbol   string
+	Interval BinanceInterval
+	Candle   Candlestick
+	Closed   bool // true when this is the final update of the candle
+}
+
+// CandleBuffer is a rolling in-memory window of candles for one symbol/interval
+type CandleBuffer struct {
+	mu      sync.RWMutex
+	size    int
+	candles []Candlestick
+}
+
+// NewCandleBuffer creates a buffer holding at most size candles
+func NewCandleBuffer(size int) *CandleBuffer {
+	if size < 1 {
+		size = 500
+	}
+	return &CandleBuffer{size: size}
+}
+
+// Update inserts a candle, replacing the last one if it has the same open time
+func (b *CandleBuffer) Update(c Candlestick) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(b.candles)
+	switch {
+	case n > 0 && b.candles[n-1].OpenTime.Equal(c.OpenTime):
+		b.candles[n-1] = c
+	case n > 0 && c.OpenTime.Before(b.candles[n-1].OpenTime):
+		return // stale update
+	default:
+		b.candles = append(b.candles, c)
+	}
+
+	if len(b.candles) > b.size {
+		b.candles = append([]Candlestick(nil), b.candles[len(b.candles)-b.size:]...)
+	}
+}
+
+// Replace swaps the buffered candles for candles (oldest first), keeping the newest size
+func (b *CandleBuffer) Replace(candles []Candlestick) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.candles = append([]Candlestick(nil), candles[max(0, len(candles)-b.size):]...)
+}
+
+// Snapshot returns a copy of the buffered candles
+func (b *CandleBuffer) Snapshot() []Candlestick {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Candlestick(nil), b.candles...)
+}
+
+// BinanceStream multiplexes Binance kline and miniTicker streams over one connection
+// Subscriptions survive reconnects and each kline stream keeps a rolling CandleBuffer
+type BinanceStream struct {
+	mu            sync.RWMutex
+	subscriptions map[string]bool          // stream names, e.g. "btcusdt@kline_1m"
+	buffers       map[string]*CandleBuffer // key: stream name
+	tickers       map[string]MiniTicker    // key: symbol
+	tickerUsed    map[string]time.Time     // key: symbol, last SubscribeMiniTicker/LastPrice call
+	bufferSize    int
+
+	klineHandlers  []func(KlineEvent)
+	tickerHandlers []func(MiniTicker)
+
+	connMu sync.Mutex
+	conn   *websocket.Conn
+	nextID int64
+}
+
+// NewBinanceStream creates a stream client keeping bufferSize candles per kline stream
+func NewBinanceStream(bufferSize int) *BinanceStream {
+	return &BinanceStream{
+		subscriptions: make(map[string]bool),
+		buffers:       make(map[string]*CandleBuffer),
+		tickers:       make(map[string]MiniTicker),
+		tickerUsed:    make(map[string]time.Time),
+		bufferSize:    bufferSize,
+	}
+}
+
+// DefaultBinanceStream is the shared stream client started by the bot
+var DefaultBinanceStream = NewBinanceStream(500)
+
+// klineStreamName returns the stream name for a symbol/interval
+func klineStreamName(symbol string, interval BinanceInterval) string {
+	return fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), interval)
+}
+
+// miniTickerStreamName returns the stream name for a symbol's mini ticker
+func miniTickerStreamName(symbol string) string {
+	return strings.ToLower(symbol) + "@miniTicker"
+}
+
+// OnKline registers a handler called for every kline update
+func (s *BinanceStream) OnKline(fn func(KlineEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.klineHandlers = append(s.klineHandlers, fn)
+}
+
+// OnTicker registers a handler called for every mini ticker update
+func (s *BinanceStream) OnTicker(fn func(MiniTicker)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tickerHandlers = append(s.tickerHandlers, fn)
+}
+
+// SubscribeKlines subscribes to a kline stream, seeding its buffer from REST
+func (s *BinanceStream) SubscribeKlines(ctx context.Context, symbol string, interval BinanceInterval) error {
+	stream := klineStreamName(symbol, interval)
+
+	s.mu.Lock()
+	if s.subscriptions[stream] {
+		s.mu.Unlock()
+		return nil
+	}
+	buffer := NewCandleBuffer(s.bufferSize)
+	s.buffers[stream] = buffer
+	s.subscriptions[stream] = true
+	s.mu.Unlock()
+
+	// Seed with history so the buffer is useful immediately
+	s.seedBuffer(ctx, stream, buffer)
+
+	return s.sendSubscription("SUBSCRIBE", []string{stream})
+}
+
+// seedBuffer fills a kline stream's buffer with the latest bufferSize candles from REST
+// On failure the buffer keeps what it has
+func (s *BinanceStream) seedBuffer(ctx context.Context, stream string, buffer *CandleBuffer) {
+	symbol, interval, _ := strings.Cut(stream, "@kline_")
+	candles, err := FetchCandlesticks(ctx, strings.ToUpper(symbol), BinanceInterval(interval), s.bufferSize)
+	if err != nil {
+		log.Printf("⚠️ [STREAM] Failed to seed %s from REST: %v", stream, err)
+		return
+	}
+	if len(candles) > 0 {
+		buffer.Replace(candles)
+	}
+}
+
+// SubscribeMiniTicker subscribes to a symbol's mini ticker stream
+// The subscription is dropped after streamTickerIdle without LastPrice calls
+func (s *BinanceStream) SubscribeMiniTicker(symbol string) error {
+	stream := miniTickerStreamName(symbol)
+
+	s.mu.Lock()
+	s.tickerUsed[strings.ToUpper(symbol)] = time.Now()
+	if s.subscriptions[stream] {
+		s.mu.Unlock()
+		return nil
+	}
+	s.subscriptions[stream] = true
+	s.mu.Unlock()
+
+	return s.sendSubscription("SUBSCRIBE", []string{stream})
+}
+
+// UnsubscribeKlines removes a kline stream and its buffer
+func (s *BinanceStream) UnsubscribeKlines(symbol string, interval BinanceInterval) error {
+	stream := klineStreamName(symbol, interval)
+
+	s.mu.Lock()
+	delete(s.subscriptions, stream)
+	delete(s.buffers, stream)
+	s.mu.Unlock()
+
+	return s.sendSubscription("UNSUBSCRIBE", []string{stream})
+}
+
+// UnsubscribeMiniTicker removes a mini ticker stream
+func (s *BinanceStream) UnsubscribeMiniTicker(symbol string) error {
+	stream := miniTickerStreamName(symbol)
+
+	s.mu.Lock()
+	delete(s.subscriptions, stream)
+	delete(s.tickers, strings.ToUpper(symbol))
+	delete(s.tickerUsed, strings.ToUpper(symbol))
+	s.mu.Unlock()
+
+	return s.sendSubscription("UNSUBSCRIBE", []string{stream})
+}
+
+// Candles returns the buffered candles for a subscribed symbol/interval
+func (s *BinanceStream) Candles(symbol string, interval BinanceInterval) []Candlestick {
+	s.mu.RLock()
+	buffer := s.buffers[klineStreamName(symbol, interval)]
+	s.mu.RUnlock()
+
+	if buffer == nil {
+		return nil
+	}
+	return buffer.Snapshot()
+}
+
+// LastPrice returns the latest streamed price for a symbol
+// Tickers older than streamMaxTickerAge are ignored so a stalled stream falls back to REST
+func (s *BinanceStream) LastPrice(symbol string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	symbol = strings.ToUpper(symbol)
+	if _, ok := s.tickerUsed[symbol]; ok {
+		s.tickerUsed[symbol] = time.Now()
+	}
+	ticker, ok := s.tickers[symbol]
+	if !ok || time.Since(ticker.EventTime) > streamMaxTickerAge {
+		return 0, false
+	}
+	return ticker.Close, true
+}
+
+// expireIdleTickers unsubscribes mini tickers idle for streamTickerIdle until ctx is cancelled
+func (s *BinanceStream) expireIdleTickers(ctx context.Context) {
+	ticker := time.NewTicker(streamIdleCheckGap)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var idle []string
+		s.mu.RLock()
+		for symbol, used := range s.tickerUsed {
+			if time.Since(used) > streamTickerIdle {
+				idle = append(idle, symbol)
+			}
+		}
+		s.mu.RUnlock()
+		for _, symbol := range idle {
+			if err := s.UnsubscribeMiniTicker(symbol); err != nil {
+				log.Printf("⚠️ [STREAM] Failed to unsubscribe idle %s ticker: %v", symbol, err)
+			}
+		}
+	}
+}
+
+// Run connects and reads until ctx is cancelled, reconnecting with exponential backoff
+// Idle mini ticker subscriptions are expired meanwhile
+func (s *BinanceStream) Run(ctx context.Context) {
+	backoff := streamMinBackoff
+	go s.expireIdleTickers(ctx)
+
+	for ctx.Err() == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			log.Printf("⚠️ [STREAM] Connect failed: %v (retry in %s)", err, backoff)
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+			backoff *= 2
+			if backoff > streamMaxBackoff {
+				backoff = streamMaxBackoff
+			}
+			continue
+		}
+
+		backoff = streamMinBackoff
+		s.setConn(conn)
+
+		// Restore all subscriptions on the new connection; kline buffers are re-seeded first
+		// so the bars that closed while disconnected are not missing from the window
+		s.mu.RLock()
+		streams := make([]string, 0, len(s.subscriptions))
+		for stream := range s.subscriptions {
+			streams = append(streams, stream)
+		}
+		buffers := make(map[string]*CandleBuffer, len(s.buffers))
+		for stream, buffer := range s.buffers {
+			buffers[stream] = buffer
+		}
+		s.mu.RUnlock()
+		for stream, buffer := range buffers {
+			s.seedBuffer(ctx, stream, buffer)
+		}
+		if len(streams) > 0 {
+			if err := s.sendSubscription("SUBSCRIBE", streams); err != nil {
+				log.Printf("⚠️ [STREAM] Resubscribe failed: %v", err)
+			}
+		}
+
+		// Close the connection when ctx is cancelled to unblock ReadMessage
+		stop := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+			case <-stop:
+			}
+		}()
+		keepAlive(conn, stop, nil)
+
+		err = s.readLoop(conn)
+		close(stop)
+		s.setConn(nil)
+		conn.Close()
+
+		// Prices from the dead connection must not be served while reconnecting
+		s.mu.Lock()
+		clear(s.tickers)
+		s.mu.Unlock()
+
+		if ctx.Err() == nil {
+			log.Printf("⚠️ [STREAM] Disconnected: %v (reconnecting)", err)
+		}
+	}
+}
+
+// dial connects to the first reachable stream endpoint
+func (s *BinanceStream) dial(ctx context.Context) (*websocket.Conn, error) {
+	var lastErr error
+	for _, streamURL := range binanceStreamURLs {
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, streamURL, nil)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to connect to %s: %w", streamURL, err)
+			continue
+		}
+		log.Printf("✅ [STREAM] Connected to %s", streamURL)
+		return conn, nil
+	}
+	return nil, lastErr
+}
+
+// setConn swaps the active connection
+func (s *BinanceStream) setConn(conn *websocket.Conn) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	s.conn = conn
+}
+
+// sendSubscription sends a SUBSCRIBE/UNSUBSCRIBE request if connected
+// When disconnected the request is skipped; Run resubscribes on reconnect
+func (s *BinanceStream) sendSubscription(method string, streams []string) error {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	s.nextID++
+	req := struct {
+		Method string   `json:"method"`
+		Params []string `json:"params"`
+		ID     int64    `json:"id"`
+	}{Method: method, Params: streams, ID: s.nextID}
+
+	if err := s.conn.WriteJSON(req); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+	return nil
+}
+
+// readLoop dispatches messages until the connection fails
+func (s *BinanceStream) readLoop(conn *websocket.Conn) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		extendReadDeadline(conn)
+
+		var envelope struct {
+			Stream string          `json:"stream"`
+			Data   json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(message, &envelope); err != nil || envelope.Stream == "" {
+			continue // subscription acknowledgements ({"result":null,"id":1})
+		}
+
+		switch {
+		case strings.Contains(envelope.Stream, "@kline_"):
+			s.handleKline(envelope.Stream, envelope.Data)
+		case strings.HasSuffix(envelope.Stream, "@miniTicker"):
+			s.handleMiniTicker(envelope.Data)
+		}
+	}
+}
+
+// handleKline updates the rolling buffer and notifies kline handlers
+func (s *BinanceStream) handleKline(stream string, data []byte) {
+	var payload struct {
+		Symbol string `json:"s"`
+		Kline  struct {
+			OpenTime  int64  `json:"t"`
+			CloseTime int64  `json:"T"`
+			Interval  string `json:"i"`
+			Open      string `json:"o"`
+			Close     string `json:"c"`
+			High      string `json:"h"`
+			Low       string `json:"l"`
+			Volume    string `json:"v"`
+			Quote     string `json:"q"`
+			Trades    int64  `json:"n"`
+			BuyBase   string `json:"V"`
+			BuyQuote  string `json:"Q"`
+			Closed    bool   `json:"x"`
+		} `json:"k"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Printf("⚠️ [STREAM] Bad kline payload on %s: %v", stream, err)
+		return
+	}
+
+	k := payload.Kline
+	open, _ := strconv.ParseFloat(k.Open, 64)
+	high, _ := strconv.ParseFloat(k.High, 64)
+	low, _ := strconv.ParseFloat(k.Low, 64)
+	close, _ := strconv.ParseFloat(k.Close, 64)
+	volume, _ := strconv.ParseFloat(k.Volume, 64)
+	quoteVolume, _ := strconv.ParseFloat(k.Quote, 64)
+	takerBuyBase, _ := strconv.ParseFloat(k.BuyBase, 64)
+	takerBuyQuote, _ := strconv.ParseFloat(k.BuyQuote, 64)
+
+	event := KlineEvent{
+		Symbol:   payload.Symbol,
+		Interval: BinanceInterval(k.Interval),
+		Closed:   k.Closed,
+		Candle: Candlestick{
+			OpenTime:      time.UnixMilli(k.OpenTime),
+			Open:          open,
+			High:          high,
+			Low:           low,
+			Close:         close,
+			Volume:        volume,
+			CloseTime:     time.UnixMilli(k.CloseTime),
+			QuoteVolume:   quoteVolume,
+			Trades:        k.Trades,
+			TakerBuyBase:  takerBuyBase,
+			TakerBuyQuote: takerBuyQuote,
+		},
+	}
+
+	s.mu.RLock()
+	buffer := s.buffers[stream]
+	handlers := s.klineHandlers
+	s.mu.RUnlock()
+
+	if buffer != nil {
+		buffer.Update(event.Candle)
+	}
+	for _, fn := range handlers {
+		fn(event)
+	}
+}
+
+// handleMiniTicker stores the latest ticker and notifies ticker handlers
+func (s *BinanceStream) handleMiniTicker(data []byte) {
+	var payload struct {
+		EventTime   int64  `json:"E"`
+		Symbol      string `json:"s"`
+		Close       string `json:"c"`
+		Open        string `json:"o"`
+		High        string `json:"h"`
+		Low         string `json:"l"`
+		Volume      string `json:"v"`
+		QuoteVolume string `json:"q"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Printf("⚠️ [STREAM] Bad miniTicker payload: %v", err)
+		return
+	}
+
+	ticker := MiniTicker{
+		Symbol:    payload.Symbol,
+		EventTime: time.UnixMilli(payload.EventTime),
+	}
+	ticker.Close, _ = strconv.ParseFloat(payload.Close, 64)
+	ticker.Open, _ = strconv.ParseFloat(payload.Open, 64)
+	ticker.High, _ = strconv.ParseFloat(payload.High, 64)
+	ticker.Low, _ = strconv.ParseFloat(payload.Low, 64)
+	ticker.Volume, _ = strconv.ParseFloat(payload.Volume, 64)
+	ticker.QuoteVolume, _ = strconv.ParseFloat(payload.QuoteVolume, 64)
+
+	s.mu.Lock()
+	s.tickers[ticker.Symbol] = ticker
+	handlers := s.tickerHandlers
+	s.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(ticker)
+	}
+}
+
+// keepAlive arms the read deadline, extends it on pings and pongs (answering pings) and pings
+// the server until stop is closed; ReadMessage then fails on a connection that went silent
+// alive (optional) is called on every ping and pong
+func keepAlive(conn *websocket.Conn, stop <-chan struct{}, alive func()) {
+	if alive == nil {
+		alive = func() {}
+	}
+	extendReadDeadline(conn)
+	conn.SetPongHandler(func(string) error {
+		extendReadDeadline(conn)
+		alive()
+		return nil
+	})
+	conn.SetPingHandler(func(data string) error {
+		extendReadDeadline(conn)
+		alive()
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(streamWriteTimeout))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	go func() {
+		ticker := time.NewTicker(streamPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				// WriteControl may run concurrently with the subscription writes
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+					return // the read deadline ends the connection
+				}
+			}
+		}
+	}()
+}
+
+// extendReadDeadline gives the connection another streamReadTimeout to deliver a frame
+func extendReadDeadline(conn *websocket.Conn) {
+	conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+}
+
+// sleepContext sleeps for d, returning false if ctx is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
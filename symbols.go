@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Symbol catalog settings
+const (
+	symbolCatalogRefresh = 6 * time.Hour
+	symbolRetryInterval  = 5 * time.Minute
+	maxSymbolSuggestions = 3
+)
+
+// defaultQuoteAssets are tried in order when the user only types a base asset (BTC -> BTCUSDT)
+var defaultQuoteAssets = []string{"USDT", "FDUSD", "USDC", "USD", "BTC", "ETH", "BNB"}
+
+// SymbolInfo is the exchangeInfo metadata of one spot symbol
+type SymbolInfo struct {
+	Symbol      string
+	BaseAsset   string
+	QuoteAsset  string
+	Status      string  // TRADING, BREAK, ...
+	TickSize    float64 // minimum price increment
+	StepSize    float64 // minimum quantity increment
+	MinQty      float64
+	MinNotional float64
+}
+
+// SymbolNotFoundError is returned when input matches no symbol, with close matches
+type SymbolNotFoundError struct {
+	Input       string
+	Suggestions []string
+}
+
+func (e *SymbolNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("symbol %s not found on Binance", e.Input)
+	}
+	return fmt.Sprintf("symbol %s not found on Binance (did you mean %s?)", e.Input, strings.Join(e.Suggestions, ", "))
+}
+
+// SymbolCatalog holds the tradable Binance spot symbols loaded from /api/v3/exchangeInfo
+type SymbolCatalog struct {
+	mu       sync.RWMutex
+	symbols  map[string]SymbolInfo
+	byBase   map[string][]SymbolInfo
+	loadedAt time.Time
+}
+
+// NewSymbolCatalog creates an empty catalog
+func NewSymbolCatalog() *SymbolCatalog {
+	return &SymbolCatalog{
+		symbols: make(map[string]SymbolInfo),
+		byBase:  make(map[string][]SymbolInfo),
+	}
+}
+
+// DefaultSymbolCatalog is the catalog used by BinanceProvider
+var DefaultSymbolCatalog = NewSymbolCatalog()
+
+// Run loads the catalog and refreshes it periodically until ctx is cancelled
+func (sc *SymbolCatalog) Run(ctx context.Context) {
+	for {
+		wait := symbolCatalogRefresh
+		if err := sc.Refresh(ctx); err != nil {
+			log.Printf("⚠️ [SYMBOLS] Failed to load exchangeInfo: %v", err)
+			wait = symbolRetryInterval
+		}
+		if !sleepContext(ctx, wait) {
+			return
+		}
+	}
+}
+
+// Refresh reloads all symbols from exchangeInfo
+func (sc *SymbolCatalog) Refresh(ctx context.Context) error {
+	body, err := fetchBinanceAPI(ctx, "/api/v3/exchangeInfo", url.Values{})
+	if err != nil {
+		return err
+	}
+
+	var raw struct {
+		Symbols []struct {
+			Symbol     string `json:"symbol"`
+			Status     string `json:"status"`
+			BaseAsset  string `json:"baseAsset"`
+			QuoteAsset string `json:"quoteAsset"`
+			Filters    []struct {
+				FilterType  string `json:"filterType"`
+				TickSize    string `json:"tickSize"`
+				StepSize    string `json:"stepSize"`
+				MinQty      string `json:"minQty"`
+				MinNotional string `json:"minNotional"`
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return fmt.Errorf("failed to parse exchangeInfo: %w", err)
+	}
+
+	symbols := make(map[string]SymbolInfo, len(raw.Symbols))
+	byBase := make(map[string][]SymbolInfo)
+	for _, s := range raw.Symbols {
+		info := SymbolInfo{
+			Symbol:     s.Symbol,
+			BaseAsset:  s.BaseAsset,
+			QuoteAsset: s.QuoteAsset,
+			Status:     s.Status,
+		}
+		for _, f := range s.Filters {
+			switch f.FilterType {
+			case "PRICE_FILTER":
+				info.TickSize, _ = strconv.ParseFloat(f.TickSize, 64)
+			case "LOT_SIZE":
+				info.StepSize, _ = strconv.ParseFloat(f.StepSize, 64)
+				info.MinQty, _ = strconv.ParseFloat(f.MinQty, 64)
+			case "NOTIONAL", "MIN_NOTIONAL":
+				info.MinNotional, _ = strconv.ParseFloat(f.MinNotional, 64)
+			}
+		}
+		symbols[info.Symbol] = info
+		byBase[info.BaseAsset] = append(byBase[info.BaseAsset], info)
+	}
+	if len(symbols) == 0 {
+		return fmt.Errorf("exchangeInfo returned no symbols")
+	}
+
+	sc.mu.Lock()
+	sc.symbols = symbols
+	sc.byBase = byBase
+	sc.loadedAt = time.Now()
+	sc.mu.Unlock()
+
+	log.Printf("✅ [SYMBOLS] Loaded %d Binance symbols", len(symbols))
+	return nil
+}
+
+// Loaded reports whether the catalog has been loaded at least once
+func (sc *SymbolCatalog) Loaded() bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return !sc.loadedAt.IsZero()
+}
+
+// Get returns the metadata of an exact symbol
+func (sc *SymbolCatalog) Get(symbol string) (SymbolInfo, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	info, ok := sc.symbols[symbol]
+	return info, ok
+}
+
+// Lookup resolves user input such as "btc", "BTC/USDT" or "eth-btc" to a trading symbol
+// Unknown input yields a *SymbolNotFoundError with suggestions
+func (sc *SymbolCatalog) Lookup(input string) (SymbolInfo, error) {
+	cleaned := strings.ToUpper(strings.TrimSpace(input))
+	if cleaned == "" {
+		return SymbolInfo{}, fmt.Errorf("empty crypto symbol")
+	}
+
+	// Explicit base/quote input (BTC/USDT, BTC-USDT, BTC_USDT) never gets a default quote
+	i := strings.IndexAny(cleaned, "/-_ ")
+	explicitQuote := i > 0 && i < len(cleaned)-1
+	compact := strings.NewReplacer("/", "", "-", "", "_", "", " ", "").Replace(cleaned)
+
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	if info, ok := sc.symbols[compact]; ok && info.Status == "TRADING" {
+		return info, nil
+	}
+
+	// Base asset only: infer the default quote
+	if !explicitQuote {
+		if info, ok := sc.defaultPair(compact); ok {
+			return info, nil
+		}
+	}
+
+	return SymbolInfo{}, &SymbolNotFoundError{Input: compact, Suggestions: sc.suggest(compact)}
+}
+
+// defaultPair returns the preferred trading pair for a base asset
+// Caller must hold sc.mu
+func (sc *SymbolCatalog) defaultPair(base string) (SymbolInfo, bool) {
+	pairs := sc.byBase[base]
+	for _, quote := range defaultQuoteAssets {
+		for _, info := range pairs {
+			if info.QuoteAsset == quote && info.Status == "TRADING" {
+				return info, true
+			}
+		}
+	}
+	return SymbolInfo{}, false
+}
+
+// suggest returns the closest trading symbols by edit distance to the symbol or its base asset
+// Caller must hold sc.mu
+func (sc *SymbolCatalog) suggest(input string) []string {
+	maxDist := len(input) / 4
+	if maxDist < 2 {
+		maxDist = 2
+	}
+
+	type candidate struct {
+		symbol string
+		dist   int
+	}
+	best := make(map[string]int)
+	consider := func(symbol string, dist int) {
+		if d, ok := best[symbol]; !ok || dist < d {
+			best[symbol] = dist
+		}
+	}
+
+	for symbol, info := range sc.symbols {
+		if info.Status != "TRADING" {
+			continue
+		}
+		if d := levenshtein(input, symbol); d <= maxDist {
+			consider(symbol, d)
+		}
+	}
+	for base := range sc.byBase {
+		if d := levenshtein(input, base); d <= maxDist {
+			if info, ok := sc.defaultPair(base); ok {
+				consider(info.Symbol, d)
+			}
+		}
+	}
+
+	candidates := make([]candidate, 0, len(best))
+	for symbol, dist := range best {
+		candidates = append(candidates, candidate{symbol, dist})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
+		}
+		// Prefer USDT pairs, then alphabetical for stable output
+		iUSDT := strings.HasSuffix(candidates[i].symbol, "USDT")
+		jUSDT := strings.HasSuffix(candidates[j].symbol, "USDT")
+		if iUSDT != jUSDT {
+			return iUSDT
+		}
+		return candidates[i].symbol < candidates[j].symbol
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSymbolSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].symbol)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between two ASCII strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
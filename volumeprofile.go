@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+)
+
+// Volume profile (volume at price) with point of control, value area and volume nodes
+
+const (
+	vpDefaultBins  = 40   // price rows of a profile
+	vpValueArea    = 0.70 // share of volume inside the value area
+	vpBodyShare    = 0.5  // share of a candle's volume spread over its body (the rest over the full range)
+	vpHVNFactor    = 1.25 // a local peak above this multiple of the mean row volume is a high volume node
+	vpLVNFactor    = 0.5  // a local trough below this multiple of the mean row volume is a low volume node
+	vpMaxNodes     = 3    // nodes of each kind reported
+	vpPanelPercent = 0.18 // chart width used by the histogram
+)
+
+// Colors for the volume profile panel
+var (
+	colorVPBar   = color.NRGBA{R: 100, G: 149, B: 237, A: 70}
+	colorVPValue = color.NRGBA{R: 100, G: 149, B: 237, A: 130}
+	colorVPPOC   = color.NRGBA{R: 255, G: 193, B: 7, A: 200}
+)
+
+// VolumeProfile is the volume traded at each price row between Low and High
+type VolumeProfile struct {
+	Low      float64
+	High     float64
+	BinSize  float64
+	Bins     []float64 // volume per row, lowest price first
+	POCIndex int
+	POC      float64 // center of the row with the most volume
+	VAH      float64 // value area high
+	VAL      float64 // value area low
+	VALIndex int     // first row inside the value area
+	VAHIndex int     // last row inside the value area
+	HVN      []float64
+	LVN      []float64
+	Total    float64
+}
+
+// ComputeVolumeProfile builds a profile with the given number of rows
+// Each candle's volume is spread over the rows it traded through: vpBodyShare evenly over the
+// body and the rest evenly over the full high-low range, so wicks get less volume than bodies
+// Returns nil when the candles carry no volume (e.g., Yahoo forex)
+func ComputeVolumeProfile(candles []Candlestick, bins int) *VolumeProfile {
+	if len(candles) == 0 || bins < 1 {
+		return nil
+	}
+
+	vp := &VolumeProfile{Low: candles[0].Low, High: candles[0].High, Bins: make([]float64, bins)}
+	for _, c := range candles {
+		vp.Low = math.Min(vp.Low, c.Low)
+		vp.High = math.Max(vp.High, c.High)
+		vp.Total += c.Volume
+	}
+	if vp.Total <= 0 || vp.High <= vp.Low {
+		return nil
+	}
+	vp.BinSize = (vp.High - vp.Low) / float64(bins)
+
+	for _, c := range candles {
+		bodyLow, bodyHigh := math.Min(c.Open, c.Close), math.Max(c.Open, c.Close)
+		vp.spread(bodyLow, bodyHigh, c.Volume*vpBodyShare)
+		vp.spread(c.Low, c.High, c.Volume*(1-vpBodyShare))
+	}
+
+	for i, v := range vp.Bins {
+		if v > vp.Bins[vp.POCIndex] {
+			vp.POCIndex = i
+		}
+	}
+	vp.POC = vp.rowPrice(vp.POCIndex)
+	vp.computeValueArea()
+	vp.computeNodes()
+	return vp
+}
+
+// spread adds volume evenly over [low, high]; a zero range goes to the row containing the price
+func (vp *VolumeProfile) spread(low, high, volume float64) {
+	if volume <= 0 {
+		return
+	}
+	if high-low < vp.BinSize*1e-9 {
+		vp.Bins[vp.row(low)] += volume
+		return
+	}
+	for i := vp.row(low); i <= vp.row(high); i++ {
+		rowLow := vp.Low + float64(i)*vp.BinSize
+		overlap := math.Min(high, rowLow+vp.BinSize) - math.Max(low, rowLow)
+		if overlap > 0 {
+			vp.Bins[i] += volume * overlap / (high - low)
+		}
+	}
+}
+
+// row returns the row index of a price
+func (vp *VolumeProfile) row(price float64) int {
+	i := int((price - vp.Low) / vp.BinSize)
+	return min(max(i, 0), len(vp.Bins)-1)
+}
+
+// rowPrice returns the center price of a row
+func (vp *VolumeProfile) rowPrice(i int) float64 {
+	return vp.Low + (float64(i)+0.5)*vp.BinSize
+}
+
+// computeValueArea grows the value area from the POC, one row at a time toward the
+// side with more volume, until it holds vpValueArea of the total
+func (vp *VolumeProfile) computeValueArea() {
+	lo, hi := vp.POCIndex, vp.POCIndex
+	volume := vp.Bins[vp.POCIndex]
+	for volume < vp.Total*vpValueArea && (lo > 0 || hi < len(vp.Bins)-1) {
+		below, above := -1.0, -1.0
+		if lo > 0 {
+			below = vp.Bins[lo-1]
+		}
+		if hi < len(vp.Bins)-1 {
+			above = vp.Bins[hi+1]
+		}
+		if above >= below {
+			hi++
+			volume += above
+		} else {
+			lo--
+			volume += below
+		}
+	}
+	vp.VALIndex, vp.VAHIndex = lo, hi
+	vp.VAL = vp.Low + float64(lo)*vp.BinSize
+	vp.VAH = vp.Low + float64(hi+1)*vp.BinSize
+}
+
+// computeNodes finds local peaks (HVN) and troughs (LVN) of the profile, strongest first
+func (vp *VolumeProfile) computeNodes() {
+	mean := vp.Total / float64(len(vp.Bins))
+	type node struct {
+		price, volume float64
+	}
+	var peaks, troughs []node
+	for i := 1; i < len(vp.Bins)-1; i++ {
+		v, prev, next := vp.Bins[i], vp.Bins[i-1], vp.Bins[i+1]
+		switch {
+		case i != vp.POCIndex && v > prev && v >= next && v >= mean*vpHVNFactor:
+			peaks = append(peaks, node{vp.rowPrice(i), v})
+		case v < prev && v <= next && v <= mean*vpLVNFactor:
+			troughs = append(troughs, node{vp.rowPrice(i), v})
+		}
+	}
+
+	// Selection by volume; few candidates so a simple pass is enough
+	pick := func(nodes []node, better func(a, b float64) bool) []float64 {
+		var out []float64
+		for len(out) < vpMaxNodes && len(nodes) > 0 {
+			best := 0
+			for i := range nodes {
+				if better(nodes[i].volume, nodes[best].volume) {
+					best = i
+				}
+			}
+			out = append(out, nodes[best].price)
+			nodes = append(nodes[:best], nodes[best+1:]...)
+		}
+		return out
+	}
+	vp.HVN = pick(peaks, func(a, b float64) bool { return a > b })
+	vp.LVN = pick(troughs, func(a, b float64) bool { return a < b })
+}
+
+// Position describes price relative to the value area
+func (vp *VolumeProfile) Position(price float64) string {
+	switch {
+	case price > vp.VAH:
+		return "ABOVE VALUE"
+	case price < vp.VAL:
+		return "BELOW VALUE"
+	default:
+		return "INSIDE VALUE"
+	}
+}
+
+// FormatVolumeProfileForAI formats the profile as lines of a timeframe section
+func FormatVolumeProfileForAI(vp *VolumeProfile, close float64, prec InstrumentPrecision) string {
+	p := prec.Format
+	list := func(prices []float64) string {
+		if len(prices) == 0 {
+			return "-"
+		}
+		parts := make([]string, len(prices))
+		for i, v := range prices {
+			parts[i] = p(v)
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Volume Profile: POC %s | VAH %s | VAL %s | Price %s\n", p(vp.POC), p(vp.VAH), p(vp.VAL), vp.Position(close)))
+	sb.WriteString(fmt.Sprintf("Volume Nodes: HVN %s | LVN %s\n", list(vp.HVN), list(vp.LVN)))
+	return sb.String()
+}
+
+// drawVolumeProfile draws the profile as a histogram anchored to the right edge of the plot,
+// highlighting the value area and the POC row
+func drawVolumeProfile(img *image.RGBA, vp *VolumeProfile, chartRight, chartTop, chartWidth, chartHeight int, maxPrice, priceRange float64) {
+	largest := vp.Bins[vp.POCIndex]
+	if largest <= 0 {
+		return
+	}
+	panelWidth := float64(chartWidth) * vpPanelPercent
+	y := func(price float64) int {
+		return chartTop + int((maxPrice-price)/priceRange*float64(chartHeight))
+	}
+
+	for i, v := range vp.Bins {
+		rowLow := vp.Low + float64(i)*vp.BinSize
+		top, bottom := y(rowLow+vp.BinSize), y(rowLow)-1
+		if bottom <= top {
+			bottom = top + 1
+		}
+		barColor := colorVPBar
+		switch {
+		case i == vp.POCIndex:
+			barColor = colorVPPOC
+		case i >= vp.VALIndex && i <= vp.VAHIndex:
+			barColor = colorVPValue
+		}
+		bar := image.Rect(chartRight-int(panelWidth*v/largest), top, chartRight, bottom)
+		draw.Draw(img, bar, &image.Uniform{barColor}, image.Point{}, draw.Over)
+	}
+
+	drawText(img, chartRight-int(panelWidth)-30, y(vp.POC)+4, "POC", opaque(colorVPPOC))
+}
@@ -1,10 +1,11 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -20,7 +21,7 @@ const (
 	YahooInterval15m YahooInterval = "15m"
 	YahooInterval30m YahooInterval = "30m"
 	YahooInterval1h  YahooInterval = "1h"
-	YahooInterval4h  YahooInterval = "4h" // Note: Yahoo uses different approach for 4h
+	YahooInterval4h  YahooInterval = "4h" // Note: not native on Yahoo, resampled from 1h
 	YahooInterval1d  YahooInterval = "1d"
 	YahooInterval1wk YahooInterval = "1wk"
 )
@@ -58,11 +59,11 @@ type YahooChartResponse struct {
 			Timestamp  []int64 `json:"timestamp"`
 			Indicators struct {
 				Quote []struct {
-					Open   []float64 `json:"open"`
-					High   []float64 `json:"high"`
-					Low    []float64 `json:"low"`
-					Close  []float64 `json:"close"`
-					Volume []int64   `json:"volume"`
+					Open   []*float64 `json:"open"` // null when the bar had no trades
+					High   []*float64 `json:"high"`
+					Low    []*float64 `json:"low"`
+					Close  []*float64 `json:"close"`
+					Volume []*int64   `json:"volume"`
 				} `json:"quote"`
 			} `json:"indicators"`
 		} `json:"result"`
@@ -88,7 +89,7 @@ func GetRangeForInterval(interval YahooInterval) YahooRange {
 	case YahooInterval1h:
 		return YahooRange3mo // 1h data for 3 months
 	case YahooInterval4h:
-		return YahooRange6mo // Simulated via 1h
+		return YahooRange6mo // Resampled from 1h
 	case YahooInterval1d:
 		return YahooRange1y // 1d data for 1 year
 	case YahooInterval1wk:
@@ -102,7 +103,8 @@ func GetRangeForInterval(interval YahooInterval) YahooRange {
 // symbol: e.g., "EURUSD=X" for forex, "AAPL" for stocks
 // interval: e.g., "5m", "1h", "1d"
 // limit: maximum number of candles to return
-func FetchYahooCandlesticks(symbol string, interval YahooInterval, limit int) ([]Candlestick, error) {
+// 4h is not served by Yahoo, so it is resampled from 1h data (session-anchored)
+func FetchYahooCandlesticks(ctx context.Context, symbol string, interval YahooInterval, limit int) ([]Candlestick, error) {
 	if limit < 1 {
 		limit = 200
 	}
@@ -110,36 +112,45 @@ func FetchYahooCandlesticks(symbol string, interval YahooInterval, limit int) ([
 	// Get appropriate range for the interval
 	yahooRange := GetRangeForInterval(interval)
 
-	// Build URL
-	url := fmt.Sprintf("%s/%s?interval=%s&range=%s",
-		YahooFinanceBaseURL, symbol, interval, yahooRange)
-
-	// Create HTTP request with headers
-	req, err := http.NewRequest("GET", url, nil)
+	var candles []Candlestick
+	var err error
+	if interval == YahooInterval4h {
+		var hourly []Candlestick
+		hourly, err = fetchYahooChart(ctx, symbol, YahooInterval1h, yahooRange)
+		candles = ResampleCandles(hourly, Interval4h, yahooSessionOffset(symbol))
+	} else {
+		candles, err = fetchYahooChart(ctx, symbol, interval, yahooRange)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	// Add headers to avoid 403/429 errors
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-
-	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from Yahoo Finance: %w", err)
+	// Limit the number of candles returned
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Yahoo Finance API error (status %d): %s", resp.StatusCode, string(body))
+	return candles, nil
+}
+
+// yahooSessionOffset returns the resampling anchor for a Yahoo symbol
+// Forex (=X) and futures (=F) trade from the 22:00 UTC session open
+func yahooSessionOffset(symbol string) time.Duration {
+	if strings.HasSuffix(symbol, "=X") || strings.HasSuffix(symbol, "=F") {
+		return ForexSessionOffset
 	}
+	return 0
+}
+
+// fetchYahooChart fetches and parses all candles of a Yahoo chart for interval/range
+func fetchYahooChart(ctx context.Context, symbol string, interval YahooInterval, yahooRange YahooRange) ([]Candlestick, error) {
+	// Build URL
+	url := fmt.Sprintf("%s/%s?interval=%s&range=%s",
+		YahooFinanceBaseURL, symbol, interval, yahooRange)
 
-	body, err := io.ReadAll(resp.Body)
+	body, _, err := DefaultHTTPClient.Get(ctx, url, yahooHeaders())
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("Yahoo Finance API error: %w", err)
 	}
 
 	// Parse JSON response
@@ -172,41 +183,38 @@ func FetchYahooCandlesticks(symbol string, interval YahooInterval, limit int) ([
 	// Build candlestick array
 	candles := make([]Candlestick, 0, len(timestamps))
 	for i := 0; i < len(timestamps); i++ {
-		// Skip if any OHLC value is missing (null in JSON becomes 0)
-		if i >= len(quote.Open) || i >= len(quote.High) ||
-			i >= len(quote.Low) || i >= len(quote.Close) {
-			continue
-		}
-
-		// Skip candles with zero/null values
-		if quote.Open[i] == 0 && quote.High[i] == 0 && quote.Low[i] == 0 && quote.Close[i] == 0 {
+		// A bar without a close has no price at all: leave the gap instead of inventing one
+		closePrice, ok := yahooValue(quote.Close, i)
+		if !ok {
 			continue
 		}
 
-		// Handle potential nil values in arrays (JSON null becomes 0 in Go)
-		openPrice := quote.Open[i]
-		highPrice := quote.High[i]
-		lowPrice := quote.Low[i]
-		closePrice := quote.Close[i]
-
-		// Use previous close for missing open
-		if openPrice == 0 && len(candles) > 0 {
-			openPrice = candles[len(candles)-1].Close
+		// Partial bars keep the close and are flagged when other fields are filled in
+		openPrice, hasOpen := yahooValue(quote.Open, i)
+		highPrice, hasHigh := yahooValue(quote.High, i)
+		lowPrice, hasLow := yahooValue(quote.Low, i)
+		synthetic := !hasOpen || !hasHigh || !hasLow
+		if !hasOpen {
+			openPrice = closePrice
+			if len(candles) > 0 {
+				openPrice = candles[len(candles)-1].Close
+			}
 		}
-		if closePrice == 0 && openPrice > 0 {
-			closePrice = openPrice
+		if !hasHigh {
+			highPrice = max(openPrice, closePrice)
 		}
-		if highPrice == 0 {
-			highPrice = openPrice
+		if !hasLow {
+			lowPrice = min(openPrice, closePrice)
 		}
-		if lowPrice == 0 {
-			lowPrice = openPrice
+		if synthetic {
+			highPrice = max(highPrice, openPrice, closePrice)
+			lowPrice = min(lowPrice, openPrice, closePrice)
 		}
 
-		// Handle volume (forex often has 0 volume)
+		// Handle volume (forex often has 0 or null volume)
 		volume := 0.0
-		if i < len(quote.Volume) {
-			volume = float64(quote.Volume[i])
+		if i < len(quote.Volume) && quote.Volume[i] != nil {
+			volume = float64(*quote.Volume[i])
 		}
 
 		// Calculate close time based on interval
@@ -238,63 +246,55 @@ func FetchYahooCandlesticks(symbol string, interval YahooInterval, limit int) ([
 			Close:     closePrice,
 			Volume:    volume,
 			CloseTime: time.Unix(closeTimeUnix, 0),
+			Synthetic: synthetic,
 		})
 	}
 
-	// Limit the number of candles returned
-	if len(candles) > limit {
-		candles = candles[len(candles)-limit:]
+	return candles, nil
+}
+
+// yahooValue returns the i-th value of a nullable quote array
+func yahooValue(values []*float64, i int) (float64, bool) {
+	if i >= len(values) || values[i] == nil || *values[i] == 0 {
+		return 0, false
 	}
+	return *values[i], true
+}
 
-	return candles, nil
+// yahooHeaders returns the request headers Yahoo Finance expects (avoids 403/429 errors)
+func yahooHeaders() http.Header {
+	header := http.Header{}
+	header.Set("User-Agent", browserUserAgent)
+	header.Set("Accept", "application/json")
+	return header
 }
 
 // ValidateYahooSymbol checks if a symbol exists on Yahoo Finance
-func ValidateYahooSymbol(symbol string) (bool, error) {
+func ValidateYahooSymbol(ctx context.Context, symbol string) (bool, error) {
 	url := fmt.Sprintf("%s/%s?interval=1d&range=1d", YahooFinanceBaseURL, symbol)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return false, err
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusOK {
+	_, _, err := DefaultHTTPClient.Get(ctx, url, yahooHeaders())
+	if err == nil {
 		return true, nil
 	}
+	if IsHTTPStatus(err, http.StatusNotFound) {
+		return false, nil
+	}
 
-	return false, nil
+	return false, err
 }
 
 // GetYahooCurrentPrice fetches the current price of a forex symbol
-func GetYahooCurrentPrice(symbol string) (float64, error) {
+func GetYahooCurrentPrice(ctx context.Context, symbol string) (float64, error) {
 	url := fmt.Sprintf("%s/%s?interval=1m&range=1d", YahooFinanceBaseURL, symbol)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return 0, err
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	body, _, err := DefaultHTTPClient.Get(ctx, url, yahooHeaders())
 	if err != nil {
+		if IsHTTPStatus(err, http.StatusNotFound) {
+			return 0, fmt.Errorf("symbol not found: %s", symbol)
+		}
 		return 0, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("symbol not found: %s", symbol)
-	}
-
-	body, _ := io.ReadAll(resp.Body)
 
 	var yahooResp YahooChartResponse
 	if err := json.Unmarshal(body, &yahooResp); err != nil {
@@ -322,7 +322,7 @@ func ConvertBinanceToYahooInterval(bi BinanceInterval) YahooInterval {
 	case Interval1h:
 		return YahooInterval1h
 	case Interval4h:
-		return YahooInterval1h // Yahoo doesn't have 4h, use 1h
+		return YahooInterval4h // Resampled from 1h by FetchYahooCandlesticks
 	case Interval1d:
 		return YahooInterval1d
 	case Interval1w:
@@ -355,3 +355,42 @@ func GetYahooTimeframeName(interval YahooInterval) string {
 		return string(interval)
 	}
 }
+
+// YahooProvider implements MarketDataProvider for forex via Yahoo Finance
+type YahooProvider struct{}
+
+// Name returns the provider name
+func (YahooProvider) Name() string { return "Yahoo Finance" }
+
+// Market returns MarketForex
+func (YahooProvider) Market() Market { return MarketForex }
+
+// NormalizeSymbol converts forex input to a Yahoo symbol via NormalizeForexSymbol
+func (YahooProvider) NormalizeSymbol(input string) (string, string, error) {
+	return NormalizeForexSymbol(input)
+}
+
+// FetchCandles fetches candles via FetchYahooCandlesticks
+func (YahooProvider) FetchCandles(ctx context.Context, symbol string, interval BinanceInterval, limit int) ([]Candlestick, error) {
+	return FetchYahooCandlesticks(ctx, symbol, ConvertBinanceToYahooInterval(interval), limit)
+}
+
+// GetCurrentPrice fetches the current price via GetYahooCurrentPrice
+func (YahooProvider) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	return GetYahooCurrentPrice(ctx, symbol)
+}
+
+// ValidateSymbol checks the symbol via ValidateYahooSymbol
+func (YahooProvider) ValidateSymbol(ctx context.Context, symbol string) (bool, error) {
+	return ValidateYahooSymbol(ctx, symbol)
+}
+
+// SupportedIntervals returns the intervals FetchYahooCandlesticks can serve (4h is resampled)
+func (YahooProvider) SupportedIntervals() []BinanceInterval {
+	return []BinanceInterval{Interval1m, Interval5m, Interval15m, Interval30m, Interval1h, Interval4h, Interval1d, Interval1w}
+}
+
+// Precision returns the pip-based precision via ForexPrecision
+func (YahooProvider) Precision(symbol string) InstrumentPrecision {
+	return ForexPrecision(symbol)
+}